@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"time"
+
+	"tiflo/internal/gc"
+	"tiflo/internal/repository"
+	"tiflo/pkg/storage"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// gc deletes orphaned media files once and prints report, by default nothing is deleted
+func main() {
+	configPath := flag.String("config", "/configs/config.yml", "path to config file")
+	mediaPath := flag.String("media", "", "local media directory, storage.local.dir from config by default")
+	dryRun := flag.Bool("dry-run", true, "only report files which would be deleted")
+	grace := flag.Duration("grace", 0, "minimal age of deleted file, gc.grace from config by default")
+	flag.Parse()
+
+	logger := logrus.New()
+	logger.SetFormatter(&logrus.TextFormatter{TimestampFormat: time.DateTime, FullTimestamp: true})
+
+	vp := viper.New()
+	vp.SetConfigFile(*configPath)
+	if err := vp.ReadInConfig(); err != nil {
+		logger.Fatalln("error reading config: ", err)
+	}
+
+	db, err := repository.NewPostgresDB(vp.GetString("db.connection_string"))
+	if err != nil {
+		logger.Fatalln("error during connecting to postgres ", err)
+	}
+	defer db.Close()
+
+	config := gc.InitConfig(vp)
+	if *grace > 0 {
+		config.Grace = *grace
+	}
+
+	storageConfig := storage.InitConfig(vp, "/media/")
+	if *mediaPath != "" {
+		storageConfig.Local.Dir = *mediaPath
+	}
+
+	mediaStorage, err := storage.NewStorage(context.Background(), storageConfig, logger)
+	if err != nil {
+		logger.Fatalln("error during connecting to storage ", err)
+	}
+
+	collector := gc.NewCollector(logger, repository.NewRepository(logger, db), config, mediaStorage)
+	report, err := collector.Collect(context.Background(), *dryRun)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err = encoder.Encode(report); err != nil {
+		logger.Fatalln(err)
+	}
+}
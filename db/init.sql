@@ -1,3 +1,7 @@
+DROP TABLE IF EXISTS lexicon_entry;
+DROP TABLE IF EXISTS note_mention;
+DROP TABLE IF EXISTS note;
+DROP TABLE IF EXISTS transcript_segment;
 DROP TABLE IF EXISTS audio_part;
 DROP TABLE IF EXISTS project;
 DROP TABLE IF EXISTS "user";
@@ -15,9 +19,24 @@ CREATE TABLE IF NOT EXISTS project
 (
     project_id uuid PRIMARY KEY default gen_random_uuid(),
     created    timestamp        default now(),
+    updated    timestamp        default now(),
+    media_type TEXT    NOT NULL default '',
     video_path TEXT             default '',
     audio_path TEXT             default '',
     image_path TEXT             default '',
+    version    bigint  NOT NULL default 1,
+    fencing_token bigint NOT NULL default 0,
+    lint_config jsonb,
+    media_probe jsonb,
+    proxy_path text,
+    hls_path text,
+    output_path text,
+    output_hls_path text,
+    thumbnails_path text,
+    ssml       boolean NOT NULL default false,
+    voice      TEXT    NOT NULL default '',
+    is_template boolean NOT NULL default false,
+    deleted_at timestamp,
     user_id    uuid
         constraint user_id_fk
             references "user" (user_id),
@@ -33,15 +52,109 @@ CREATE TABLE IF NOT EXISTS audio_part
     start      int,
     duration   int,
     text       TEXT                      default '',
-    path       TEXT                      default ''
+    path       TEXT                      default '',
+    voice_input TEXT NOT NULL            default '',
+    voice      TEXT NOT NULL             default '',
+    status     TEXT NOT NULL             default 'draft'
+        constraint status_check
+            check (status in ('draft', 'needs_review', 'approved', 'rejected')),
+    reviewer_id uuid
+        constraint reviewer_id_fk
+            references "user" (user_id),
+    reviewed   timestamp
+);
+
+CREATE TABLE IF NOT EXISTS transcript_segment
+(
+    segment_id uuid NOT NULL PRIMARY KEY default gen_random_uuid(),
+    project_id uuid NOT NULL
+        constraint transcript_segment_project_id_fk
+            references project (project_id) ON DELETE CASCADE,
+    start      bigint NOT NULL,
+    duration   bigint NOT NULL,
+    text       TEXT   NOT NULL default '',
+    speaker    TEXT   NOT NULL default ''
+);
+
+CREATE TABLE IF NOT EXISTS note
+(
+    note_id     uuid NOT NULL PRIMARY KEY default gen_random_uuid(),
+    project_id  uuid NOT NULL
+        constraint note_project_id_fk
+            references project (project_id) ON DELETE CASCADE,
+    parent_id   uuid
+        constraint note_parent_id_fk
+            references note (note_id) ON DELETE CASCADE,
+    part_id     uuid
+        constraint note_part_id_fk
+            references audio_part (part_id) ON DELETE SET NULL,
+    time        bigint  NOT NULL default 0,
+    user_id     uuid    NOT NULL
+        constraint note_user_id_fk
+            references "user" (user_id),
+    text        TEXT    NOT NULL default '',
+    created     timestamp        default now(),
+    resolved    boolean NOT NULL default false,
+    resolved_by uuid
+        constraint note_resolved_by_fk
+            references "user" (user_id),
+    resolved_at timestamp
+);
+
+CREATE TABLE IF NOT EXISTS note_mention
+(
+    note_id uuid NOT NULL
+        constraint note_mention_note_id_fk
+            references note (note_id) ON DELETE CASCADE,
+    user_id uuid NOT NULL
+        constraint note_mention_user_id_fk
+            references "user" (user_id),
+    PRIMARY KEY (note_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS project_user_created_idx ON project (user_id, created, project_id);
+CREATE INDEX IF NOT EXISTS project_user_updated_idx ON project (user_id, updated, project_id);
+CREATE INDEX IF NOT EXISTS project_user_name_idx ON project (user_id, name, project_id);
+CREATE INDEX IF NOT EXISTS audio_part_project_idx ON audio_part (project_id, start);
+CREATE INDEX IF NOT EXISTS transcript_segment_project_idx ON transcript_segment (project_id, start);
+CREATE INDEX IF NOT EXISTS project_deleted_at_idx ON project (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- full-text search over descriptions, expressions must match the ones in search queries
+CREATE INDEX IF NOT EXISTS audio_part_text_ru_idx
+    ON audio_part USING GIN (to_tsvector('russian', COALESCE(text, '')));
+CREATE INDEX IF NOT EXISTS audio_part_text_en_idx
+    ON audio_part USING GIN (to_tsvector('english', COALESCE(text, '')));
+
+CREATE TABLE IF NOT EXISTS lexicon_entry
+(
+    entry_id   uuid NOT NULL PRIMARY KEY default gen_random_uuid(),
+    user_id    uuid NOT NULL
+        constraint lexicon_user_id_fk
+            references "user" (user_id) ON DELETE CASCADE,
+    project_id uuid
+        constraint lexicon_project_id_fk
+            references project (project_id) ON DELETE CASCADE,
+    term       TEXT NOT NULL,
+    alias      TEXT NOT NULL             default '',
+    phoneme    TEXT NOT NULL             default '',
+    alphabet   TEXT NOT NULL             default '',
+    created    timestamp                 default now()
 );
 
+CREATE UNIQUE INDEX IF NOT EXISTS lexicon_entry_term_idx
+    ON lexicon_entry (user_id, COALESCE(project_id, '00000000-0000-0000-0000-000000000000'), lower(term));
+
 CREATE OR REPLACE FUNCTION increment_project_name()
     RETURNS TRIGGER AS
 $$
 DECLARE
     next_project_number INTEGER;
 BEGIN
+    -- duplicated projects come with their own name
+    IF NEW.name IS NOT NULL AND NEW.name <> '' THEN
+        RETURN NEW;
+    END IF;
+
     SELECT COUNT(*) + 1
     INTO next_project_number
     FROM project
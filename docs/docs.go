@@ -132,30 +132,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/projects/": {
+        "/api/lexicon": {
             "get": {
-                "description": "Get all user' projects as an array",
+                "description": "Get pronunciation entries which are used in all user's projects",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "Lexicon"
                 ],
-                "summary": "Get all user' projects info",
+                "summary": "Get user lexicon",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/model.Project"
+                                "$ref": "#/definitions/model.LexiconEntry"
                             }
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {}
-                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {}
@@ -167,19 +163,33 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Create a  new project with default name",
+                "description": "Add pronunciation entry which is used in all user's projects",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "Lexicon"
+                ],
+                "summary": "Add user lexicon entry",
+                "parameters": [
+                    {
+                        "description": "Term and its pronunciation",
+                        "name": "entry",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.LexiconEntryCreate"
+                        }
+                    }
                 ],
-                "summary": "Create new user project",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Project"
+                            "$ref": "#/definitions/model.LexiconEntry"
                         }
                     },
                     "400": {
@@ -190,6 +200,10 @@ const docTemplate = `{
                         "description": "Unauthorized",
                         "schema": {}
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {}
@@ -197,21 +211,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/projects/{projectId}": {
-            "get": {
-                "description": "Get project name, path to media and audio parts",
+        "/api/lexicon/{entryId}": {
+            "delete": {
+                "description": "Delete pronunciation entry of user lexicon",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "Lexicon"
                 ],
-                "summary": "Get project info",
+                "summary": "Delete user lexicon entry",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Project Id",
-                        "name": "projectId",
+                        "description": "Entry Id",
+                        "name": "entryId",
                         "in": "path",
                         "required": true
                     }
@@ -220,7 +234,8 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Project"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
@@ -231,44 +246,72 @@ const docTemplate = `{
                         "description": "Unauthorized",
                         "schema": {}
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {}
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete project",
+            }
+        },
+        "/api/media/{name}": {
+            "get": {
+                "description": "Download media file by signed URL got from project info, Range requests are supported.\nURLs in HLS playlists and WebVTT thumbnail indexes are signed the same way.\nMedia of S3 storage is redirected to presigned URL of storage.",
                 "produces": [
-                    "application/json"
+                    "application/octet-stream"
                 ],
                 "tags": [
-                    "Project"
+                    "Media"
                 ],
-                "summary": "Delete project",
+                "summary": "Download media file",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Project Id",
-                        "name": "projectId",
+                        "description": "Media file name",
+                        "name": "name",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Expiration time of URL, unix seconds",
+                        "name": "expires",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Signature of URL",
+                        "name": "signature",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "type": "file"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "206": {
+                        "description": "Partial Content",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "307": {
+                        "description": "Temporary Redirect"
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {}
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {}
                     },
                     "500": {
@@ -276,43 +319,79 @@ const docTemplate = `{
                         "schema": {}
                     }
                 }
-            },
-            "patch": {
-                "description": "rename project",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/api/projects/": {
+            "get": {
+                "description": "Get page of user' projects. Next page is requested with nextCursor of previous one and the same sort and order",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Project"
                 ],
-                "summary": "Update project name",
+                "summary": "Get user' projects",
                 "parameters": [
                     {
-                        "description": "New project name",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.ProjectUpdate"
-                        }
+                        "type": "integer",
+                        "description": "Page size, 20 by default, 100 at most",
+                        "name": "limit",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Project Id",
-                        "name": "projectId",
-                        "in": "path",
-                        "required": true
+                        "description": "nextCursor from previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort field: created (default), name, updated",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc, by default desc for dates and asc for name",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by media type: video, image, audio, none",
+                        "name": "mediaType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by status: new, draft, in_review, ready",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search in project name",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return only templates or only ordinary projects",
+                        "name": "template",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return projects without audio parts",
+                        "name": "summary",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/model.ProjectList"
                         }
                     },
                     "400": {
@@ -328,33 +407,29 @@ const docTemplate = `{
                         "schema": {}
                     }
                 }
-            }
-        },
-        "/api/projects/{projectId}/audio": {
+            },
             "post": {
-                "description": "Get path for audio file got from all audio parts",
+                "description": "Create a  new project with default name. Project created from template inherits its voice, lint rules and lexicon",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Audio"
+                    "Project"
                 ],
-                "summary": "Get final audio",
+                "summary": "Create new user project",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Project Id",
-                        "name": "projectId",
-                        "in": "path",
-                        "required": true
+                        "description": "Id of user's template project",
+                        "name": "templateId",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/model.Project"
                         }
                     },
                     "400": {
@@ -365,6 +440,10 @@ const docTemplate = `{
                         "description": "Unauthorized",
                         "schema": {}
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {}
@@ -372,50 +451,33 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/projects/{projectId}/audio-part/{audioPartId}": {
-            "put": {
-                "description": "Change text comment for chosen audio part",
+        "/api/projects/import": {
+            "post": {
+                "description": "Create project from archive made by export, project and media files get new ids",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Audio part"
+                    "Project"
                 ],
-                "summary": "Change text comment",
+                "summary": "Import project",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Project Id",
-                        "name": "projectId",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Audio part Id",
-                        "name": "audioPartId",
-                        "in": "path",
+                        "type": "file",
+                        "description": "Archive, .zip or .tar",
+                        "name": "file",
+                        "in": "formData",
                         "required": true
-                    },
-                    {
-                        "description": "New text for comment",
-                        "name": "comment",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.Comment"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/model.Project"
                         }
                     },
                     "400": {
@@ -426,21 +488,58 @@ const docTemplate = `{
                         "description": "Unauthorized",
                         "schema": {}
                     },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {}
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {}
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete audio part",
+            }
+        },
+        "/api/projects/trash": {
+            "get": {
+                "description": "Get user' projects in trash, they are deleted permanently at purgeAt",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Audio part"
+                    "Project"
                 ],
-                "summary": "Delete audio part",
+                "summary": "Get deleted projects",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handler.TrashProject"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/projects/trash/{projectId}/restore": {
+            "post": {
+                "description": "Take project out of trash",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Project"
+                ],
+                "summary": "Restore deleted project",
                 "parameters": [
                     {
                         "type": "string",
@@ -448,13 +547,6 @@ const docTemplate = `{
                         "name": "projectId",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Audio part Id",
-                        "name": "audioPartId",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
@@ -463,6 +555,12 @@ const docTemplate = `{
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Project version"
+                            }
                         }
                     },
                     "400": {
@@ -473,6 +571,10 @@ const docTemplate = `{
                         "description": "Unauthorized",
                         "schema": {}
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {}
@@ -480,36 +582,36 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/projects/{projectId}/image/comment": {
-            "post": {
-                "description": "Create tiflo comment for given image",
-                "consumes": [
-                    "application/json"
-                ],
+        "/api/projects/{projectId}": {
+            "get": {
+                "description": "Get project name, URLs of media, editing proxy, HLS playlists and thumbnails index, and audio parts",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Comment"
+                    "Project"
                 ],
-                "summary": "Create tiflo comment",
+                "summary": "Get project info",
                 "parameters": [
                     {
-                        "description": "name of image(uuid)",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.Image"
-                        }
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/model.Project"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Project version"
+                            }
                         }
                     },
                     "400": {
@@ -525,58 +627,196 @@ const docTemplate = `{
                         "schema": {}
                     }
                 }
-            }
-        },
-        "/api/projects/{projectId}/media": {
-            "post": {
-                "description": "Uploads a media file to the server",
-                "consumes": [
-                    "multipart/form-data"
-                ],
+            },
+            "delete": {
+                "description": "Move project to trash, it can be restored until retention period is over",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Project"
                 ],
-                "summary": "Upload media file for project",
+                "summary": "Delete project",
                 "parameters": [
-                    {
-                        "type": "file",
-                        "description": "Media file to upload",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
-                    },
                     {
                         "type": "string",
                         "description": "Project Id",
                         "name": "projectId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project version got from ETag",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully uploaded",
+                        "description": "OK",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "Failed to save file",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "patch": {
+                "description": "rename project",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Project"
+                ],
+                "summary": "Update project name",
+                "parameters": [
+                    {
+                        "description": "New project name",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProjectUpdate"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project version got from ETag",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
                     }
                 }
             }
         },
-        "/api/projects/{projectId}/video/comment": {
+        "/api/projects/{projectId}/audio": {
             "post": {
-                "description": "Create comment on video using split point",
+                "description": "Get path for audio file got from all audio parts, audio-only HLS of it is made in background",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audio"
+                ],
+                "summary": "Get final audio",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project version got from ETag",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Render even if some descriptions are not approved",
+                        "name": "force",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        },
+                        "headers": {
+                            "X-Not-Approved": {
+                                "type": "int",
+                                "description": "Number of descriptions which are not approved, set on forced render"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.DeliveryReadiness"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/projects/{projectId}/audio-part/{audioPartId}": {
+            "put": {
+                "description": "Change text comment for chosen audio part",
                 "consumes": [
                     "application/json"
                 ],
@@ -584,9 +824,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Comment"
+                    "Audio part"
                 ],
-                "summary": "Create comment on video",
+                "summary": "Change text comment",
                 "parameters": [
                     {
                         "type": "string",
@@ -596,7 +836,21 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Split point",
+                        "type": "string",
+                        "description": "Audio part Id",
+                        "name": "audioPartId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project version got from ETag",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "New text for comment",
                         "name": "comment",
                         "in": "body",
                         "required": true,
@@ -609,7 +863,77 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Project"
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {}
+                    },
+                    "423": {
+                        "description": "Locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete audio part",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audio part"
+                ],
+                "summary": "Delete audio part",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Audio part Id",
+                        "name": "audioPartId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project version got from ETag",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
@@ -620,6 +944,25 @@ const docTemplate = `{
                         "description": "Unauthorized",
                         "schema": {}
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {}
+                    },
+                    "423": {
+                        "description": "Locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {}
@@ -627,9 +970,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/projects/{projectId}/voice": {
-            "post": {
-                "description": "Voice the given text",
+        "/api/projects/{projectId}/audio-part/{audioPartId}/status": {
+            "patch": {
+                "description": "Move audio part description to draft, needs_review, approved or rejected status.\nCaller is recorded as reviewer, for now only project owner has access to project and reviews it.",
                 "consumes": [
                     "application/json"
                 ],
@@ -637,17 +980,38 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "Review"
                 ],
-                "summary": "Voice the given text",
+                "summary": "Change review status of audio part",
                 "parameters": [
                     {
-                        "description": "text which you want to be voiced",
-                        "name": "user",
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Audio part Id",
+                        "name": "audioPartId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project version got from ETag",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "New status",
+                        "name": "status",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.VoiceText"
+                            "$ref": "#/definitions/model.PartStatusUpdate"
                         }
                     }
                 ],
@@ -655,8 +1019,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/model.AudioPart"
                         }
                     },
                     "400": {
@@ -667,64 +1030,2460 @@ const docTemplate = `{
                         "description": "Unauthorized",
                         "schema": {}
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
                         "schema": {}
+                    },
+                    "423": {
+                        "description": "Locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/projects/{projectId}/duplicate": {
+            "post": {
+                "description": "Deep copy of project with its audio parts and lexicon, media files are copied on request.\nSource project is not changed, If-Match makes sure that the version seen by client is copied.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Project"
+                ],
+                "summary": "Duplicate project",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project version got from ETag",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Name of new project and whether to copy media",
+                        "name": "duplicate",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProjectDuplicate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Project"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/projects/{projectId}/export": {
+            "get": {
+                "description": "Download archive with project, audio parts, lexicon, media files and manifest with checksums",
+                "produces": [
+                    "application/zip",
+                    "application/x-tar"
+                ],
+                "tags": [
+                    "Project"
+                ],
+                "summary": "Export project",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "zip (default) or tar",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Export even if some descriptions are not approved",
+                        "name": "force",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        },
+                        "headers": {
+                            "X-Not-Approved": {
+                                "type": "int",
+                                "description": "Number of descriptions which are not approved, set on forced export"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.DeliveryReadiness"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/projects/{projectId}/frame": {
+            "get": {
+                "description": "Get frame of project video at given time, for image projects the image itself is returned",
+                "produces": [
+                    "image/png"
+                ],
+                "tags": [
+                    "Search"
+                ],
+                "summary": "Get frame preview",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Video time in tenths of a second, without durations of descriptions",
+                        "name": "start",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/projects/{projectId}/image/comment": {
+            "post": {
+                "description": "Create tiflo comment for image of project, project has to be made from image",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Comment"
+                ],
+                "summary": "Create tiflo comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project version got from ETag",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {}
+                    },
+                    "423": {
+                        "description": "Locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/projects/{projectId}/lexicon": {
+            "get": {
+                "description": "Get pronunciation entries used in project: user entries and project entries, which override them",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Lexicon"
+                ],
+                "summary": "Get project lexicon",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.LexiconEntry"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "description": "Add pronunciation entry used only in this project",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Lexicon"
+                ],
+                "summary": "Add project lexicon entry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project version got from ETag",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Term and its pronunciation",
+                        "name": "entry",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.LexiconEntryCreate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.LexiconEntry"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/projects/{projectId}/lexicon/{entryId}": {
+            "delete": {
+                "description": "Delete pronunciation entry of project lexicon",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Lexicon"
+                ],
+                "summary": "Delete project lexicon entry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entry Id",
+                        "name": "entryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project version got from ETag",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/projects/{projectId}/lint": {
+            "get": {
+                "description": "Check all descriptions of project against style guide",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Lint"
+                ],
+                "summary": "Check project descriptions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.LintWarning"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/projects/{projectId}/lint/config": {
+            "get": {
+                "description": "Get style guide which is used to check project descriptions",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Lint"
+                ],
+                "summary": "Get project style guide",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.LintConfig"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "put": {
+                "description": "Set own style guide of project, empty body resets project to organisation style guide",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Lint"
+                ],
+                "summary": "Set project style guide",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project version got from ETag",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Style guide",
+                        "name": "config",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/model.LintConfig"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/projects/{projectId}/media": {
+            "post": {
+                "description": "Uploads image (.jpeg, .jpg, .png), video (.mp4, .mov, .mkv, .webm) or audio (.wav, .mp3, .flac). Video which can not be played in browser is transcoded to mp4, audio-only project gets timeline without frames",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Project"
+                ],
+                "summary": "Upload media file for project",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Media file to upload",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project version got from ETag",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully uploaded",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "412": {
+                        "description": "Project was changed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "413": {
+                        "description": "File is too large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "File content is not supported or exceeds limits",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "423": {
+                        "description": "Project is being edited",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to save file",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/projects/{projectId}/notes": {
+            "get": {
+                "description": "Get note threads of project, replies are nested into root notes",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Note"
+                ],
+                "summary": "Get project notes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Note"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "description": "Create note anchored to audio part or to timestamp (hh:mm:ss.ms) of described timeline, or reply\nto existing note. Note anchored to timestamp stays at the same moment of video when descriptions change.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Note"
+                ],
+                "summary": "Create reviewer note",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project version got from ETag",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Note",
+                        "name": "note",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.NoteCreate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Note"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/projects/{projectId}/notes/{noteId}/resolve": {
+            "patch": {
+                "description": "Mark note thread as resolved or unresolved",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Note"
+                ],
+                "summary": "Resolve or reopen note thread",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Note Id",
+                        "name": "noteId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project version got from ETag",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Resolved flag",
+                        "name": "resolve",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.NoteResolve"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/projects/{projectId}/readiness": {
+            "get": {
+                "description": "Project is ready when all its descriptions are approved",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Review"
+                ],
+                "summary": "Check if project is ready for delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.DeliveryReadiness"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/projects/{projectId}/replace": {
+            "post": {
+                "description": "Replace text in all descriptions of project, changed parts are re-voiced and later parts are shifted by new durations",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audio part"
+                ],
+                "summary": "Find/replace in descriptions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project version got from ETag",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "What to find and replace, mode is literal, regex or word",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ReplaceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {}
+                    },
+                    "423": {
+                        "description": "Locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/projects/{projectId}/replace/preview": {
+            "post": {
+                "description": "Show descriptions of project which would be changed by find/replace",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audio part"
+                ],
+                "summary": "Preview find/replace",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "What to find and replace, mode is literal, regex or word",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ReplaceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.ReplacePreview"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/projects/{projectId}/revoice": {
+            "post": {
+                "description": "Re-voice descriptions whose TTS input or voice changed after lexicon or voice settings were edited, later parts are shifted by change of duration",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Lexicon"
+                ],
+                "summary": "Re-voice project descriptions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project version got from ETag",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {}
+                    },
+                    "423": {
+                        "description": "Locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/projects/{projectId}/template": {
+            "put": {
+                "description": "Template keeps voice, lint rules and lexicon which projects created from it inherit",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Project"
+                ],
+                "summary": "Mark project as template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project version got from ETag",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Whether project is template",
+                        "name": "template",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ProjectTemplate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/projects/{projectId}/transcript": {
+            "get": {
+                "description": "Timed dialogue segments of project audio",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transcript"
+                ],
+                "summary": "Get transcript",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.TranscriptSegment"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "description": "Recognise dialogue of project audio, previous transcript is replaced",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transcript"
+                ],
+                "summary": "Transcribe dialogue",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project version got from ETag",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.TranscriptSegment"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {}
+                    },
+                    "423": {
+                        "description": "Locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/projects/{projectId}/transcript/export": {
+            "get": {
+                "description": "Dialogue and descriptions interleaved by time in one accessible document",
+                "produces": [
+                    "text/html",
+                    "text/plain"
+                ],
+                "tags": [
+                    "Transcript"
+                ],
+                "summary": "Export described transcript",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "html (default) or txt",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Export even if some descriptions are not approved",
+                        "name": "force",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        },
+                        "headers": {
+                            "X-Not-Approved": {
+                                "type": "int",
+                                "description": "Number of descriptions which are not approved, set on forced export"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.DeliveryReadiness"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/projects/{projectId}/uploads": {
+            "post": {
+                "description": "Creates tus upload of project media. File name is passed in Upload-Metadata as \"filename \u003cbase64\u003e\".\nLocation of created upload is returned, file is sent to it with PATCH requests.\nProject version is checked only here, media is saved over changes made during upload.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Upload"
+                ],
+                "summary": "Create resumable upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Protocol version, 1.0.0",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Size of file",
+                        "name": "Upload-Length",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Metadata of file",
+                        "name": "Upload-Metadata",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project version got from ETag",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {}
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/projects/{projectId}/uploads/{uploadId}": {
+            "delete": {
+                "description": "Deletes upload and received chunks",
+                "tags": [
+                    "Upload"
+                ],
+                "summary": "Cancel resumable upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Upload Id",
+                        "name": "uploadId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Protocol version, 1.0.0",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "head": {
+                "description": "Returns number of received bytes in Upload-Offset header, upload is resumed from it",
+                "tags": [
+                    "Upload"
+                ],
+                "summary": "Get offset of resumable upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Upload Id",
+                        "name": "uploadId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Protocol version, 1.0.0",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "patch": {
+                "description": "Appends request body to upload at Upload-Offset, part of body received before connection is broken\nis kept and new offset is returned in Upload-Offset. When the last chunk is received, file is processed\nthe same way as uploaded with /media under edit lock of project and new project ETag is returned.\nIf file is not saved, chunks are kept and empty chunk at Upload-Length completes upload again.",
+                "consumes": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Upload"
+                ],
+                "summary": "Upload chunk of file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Upload Id",
+                        "name": "uploadId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Protocol version, 1.0.0",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset of chunk",
+                        "name": "Upload-Offset",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {}
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {}
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {}
+                    },
+                    "423": {
+                        "description": "Locked",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/projects/{projectId}/video/comment": {
+            "post": {
+                "description": "Create comment on video using split point, frames around video time are captioned together\nwith descriptions of neighbouring audio parts, recognised on-screen text is appended",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Comment"
+                ],
+                "summary": "Create comment on video",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project version got from ETag",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Split point",
+                        "name": "comment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Comment"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.LintedProject"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {}
+                    },
+                    "423": {
+                        "description": "Locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/projects/{projectId}/voice": {
+            "post": {
+                "description": "Voice the given text with lexicon and voice settings of project. Project is not changed,\nso If-Match is not required: voiced file is attached to project only by comment requests.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Project"
+                ],
+                "summary": "Voice the given text",
+                "parameters": [
+                    {
+                        "description": "text which you want to be voiced",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.VoiceText"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/projects/{projectId}/voice/settings": {
+            "put": {
+                "description": "Set TTS voice and switch between plain text and SSML input. Already voiced parts are not changed, use revoice for it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Lexicon"
+                ],
+                "summary": "Set project voice settings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project version got from ETag",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Voice settings",
+                        "name": "settings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.VoiceSettings"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/projects/{projectId}/waveform": {
+            "get": {
+                "description": "Min/max peaks of project audio or of audio part in audiowaveform format, JSON or binary (.dat)",
+                "produces": [
+                    "application/json",
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Audio"
+                ],
+                "summary": "Get waveform",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Id",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Audio part Id, audio of project by default",
+                        "name": "partId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Samples per pixel, the smallest configured zoom by default",
+                        "name": "zoom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json (default) or dat",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/waveform.Waveform"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/search": {
+            "get": {
+                "description": "Full-text search over descriptions of all user's projects",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Search"
+                ],
+                "summary": "Search descriptions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query, supports quotes, OR and -word",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ru or en, both languages are used by default",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search only in this project",
+                        "name": "projectId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 20 by default, 100 at most",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.SearchHit"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "handler.ProjectDuplicate": {
+            "type": "object",
+            "properties": {
+                "copyMedia": {
+                    "description": "CopyMedia makes copies of media files, otherwise both projects use the same files",
+                    "type": "boolean"
+                },
+                "name": {
+                    "description": "Name of new project, by default name of source project with suffix",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ProjectTemplate": {
+            "type": "object",
+            "properties": {
+                "isTemplate": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "handler.ProjectUpdate": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.TrashProject": {
+            "type": "object",
+            "required": [
+                "name",
+                "path",
+                "projectId",
+                "userId"
+            ],
+            "properties": {
+                "audioParts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.AudioPart"
+                    }
+                },
+                "created": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "type": "string"
+                },
+                "hlsPath": {
+                    "type": "string"
+                },
+                "hlsUrl": {
+                    "type": "string"
+                },
+                "isTemplate": {
+                    "type": "boolean"
+                },
+                "lintConfig": {
+                    "$ref": "#/definitions/model.LintConfig"
+                },
+                "mediaType": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "outputHlsPath": {
+                    "type": "string"
+                },
+                "outputHlsUrl": {
+                    "type": "string"
+                },
+                "outputPath": {
+                    "description": "OutputPath is described audio of the latest render",
+                    "type": "string"
+                },
+                "outputUrl": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "previewPath": {
+                    "type": "string"
+                },
+                "previewUrl": {
+                    "type": "string"
+                },
+                "probe": {
+                    "$ref": "#/definitions/model.MediaProbe"
+                },
+                "projectId": {
+                    "type": "string"
+                },
+                "proxyPath": {
+                    "description": "ProxyPath is low-bitrate mp4 editing proxy of video, it is played in browser instead of original\nwhich is kept as uploaded. HlsPath and OutputHlsPath are master playlists of uploaded\nmedia and of described audio made after render, they are made in background and may be empty",
+                    "type": "string"
+                },
+                "proxyUrl": {
+                    "description": "ProxyUrl, HlsUrl, OutputHlsUrl and ThumbnailsUrl are URLs of proxy, playlists and thumbnails index,\nthey are filled in project info",
+                    "type": "string"
+                },
+                "purgeAt": {
+                    "description": "PurgeAt is time when project is deleted permanently",
+                    "type": "string"
+                },
+                "ssml": {
+                    "type": "boolean"
+                },
+                "status": {
+                    "description": "Status is filled only in project list, see ProjectStatus* constants",
+                    "type": "string"
+                },
+                "thumbnailsPath": {
+                    "description": "ThumbnailsPath is WebVTT index of thumbnail sprite sheets of video, it is made in background too",
+                    "type": "string"
+                },
+                "thumbnailsUrl": {
+                    "type": "string"
+                },
+                "updated": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                },
+                "videoUrl": {
+                    "description": "VideoUrl and PreviewUrl are download URLs of VideoPath and ImagePath, they are filled in project info and list",
+                    "type": "string"
+                },
+                "voice": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.AudioPart": {
+            "type": "object",
+            "properties": {
+                "duration": {
+                    "type": "integer"
+                },
+                "partId": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "projectId": {
+                    "type": "string"
+                },
+                "reviewed": {
+                    "type": "string"
+                },
+                "reviewerId": {
+                    "description": "ReviewerId is user who changed review status last, only project owner reviews until project has members",
+                    "type": "string"
+                },
+                "start": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "url": {
+                    "description": "Url is download URL of Path, it is filled in project info and list",
+                    "type": "string"
+                }
+            }
+        },
+        "model.AudioStream": {
+            "type": "object",
+            "properties": {
+                "channels": {
+                    "type": "integer"
+                },
+                "codec": {
+                    "type": "string"
+                },
+                "sampleRate": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.Comment": {
+            "type": "object",
+            "properties": {
+                "splitPoint": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "videoTime": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.DeliveryReadiness": {
+            "type": "object",
+            "properties": {
+                "counts": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "notApproved": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "ready": {
+                    "type": "boolean"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.LexiconEntry": {
+            "type": "object",
+            "properties": {
+                "alias": {
+                    "description": "Alias is text which is read instead of term",
+                    "type": "string"
+                },
+                "alphabet": {
+                    "type": "string"
+                },
+                "created": {
+                    "type": "string"
+                },
+                "entryId": {
+                    "type": "string"
+                },
+                "phoneme": {
+                    "description": "Phoneme is pronunciation of term in Alphabet, it is used only in SSML mode",
+                    "type": "string"
+                },
+                "projectId": {
+                    "type": "string"
+                },
+                "term": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.LexiconEntryCreate": {
+            "type": "object",
+            "required": [
+                "term"
+            ],
+            "properties": {
+                "alias": {
+                    "type": "string"
+                },
+                "alphabet": {
+                    "type": "string"
+                },
+                "phoneme": {
+                    "type": "string"
+                },
+                "term": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.LintConfig": {
+            "type": "object",
+            "properties": {
+                "bannedPhrases": {
+                    "description": "BannedPhrases are matched case-insensitively by whole words",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "disabledRules": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "maxLength": {
+                    "description": "MaxLength is max count of characters in description",
+                    "type": "integer"
+                },
+                "maxWordsPerSecond": {
+                    "description": "MaxWordsPerSecond is compared with duration of voiced part",
+                    "type": "number"
+                },
+                "repeatedWords": {
+                    "description": "RepeatedWords is length of word sequence which should not be repeated in neighbouring parts",
+                    "type": "integer"
+                },
+                "subjectiveWords": {
+                    "description": "SubjectiveWords are word stems, every word which starts with stem is reported",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "model.LintWarning": {
+            "type": "object",
+            "properties": {
+                "fragment": {
+                    "description": "Fragment is a part of text which caused warning",
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "partId": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.LintedProject": {
+            "type": "object",
+            "required": [
+                "name",
+                "path",
+                "projectId",
+                "userId"
+            ],
+            "properties": {
+                "audioParts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.AudioPart"
+                    }
+                },
+                "created": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "type": "string"
+                },
+                "hlsPath": {
+                    "type": "string"
+                },
+                "hlsUrl": {
+                    "type": "string"
+                },
+                "isTemplate": {
+                    "type": "boolean"
+                },
+                "lintConfig": {
+                    "$ref": "#/definitions/model.LintConfig"
+                },
+                "mediaType": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "outputHlsPath": {
+                    "type": "string"
+                },
+                "outputHlsUrl": {
+                    "type": "string"
+                },
+                "outputPath": {
+                    "description": "OutputPath is described audio of the latest render",
+                    "type": "string"
+                },
+                "outputUrl": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "previewPath": {
+                    "type": "string"
+                },
+                "previewUrl": {
+                    "type": "string"
+                },
+                "probe": {
+                    "$ref": "#/definitions/model.MediaProbe"
+                },
+                "projectId": {
+                    "type": "string"
+                },
+                "proxyPath": {
+                    "description": "ProxyPath is low-bitrate mp4 editing proxy of video, it is played in browser instead of original\nwhich is kept as uploaded. HlsPath and OutputHlsPath are master playlists of uploaded\nmedia and of described audio made after render, they are made in background and may be empty",
+                    "type": "string"
+                },
+                "proxyUrl": {
+                    "description": "ProxyUrl, HlsUrl, OutputHlsUrl and ThumbnailsUrl are URLs of proxy, playlists and thumbnails index,\nthey are filled in project info",
+                    "type": "string"
+                },
+                "ssml": {
+                    "type": "boolean"
+                },
+                "status": {
+                    "description": "Status is filled only in project list, see ProjectStatus* constants",
+                    "type": "string"
+                },
+                "thumbnailsPath": {
+                    "description": "ThumbnailsPath is WebVTT index of thumbnail sprite sheets of video, it is made in background too",
+                    "type": "string"
+                },
+                "thumbnailsUrl": {
+                    "type": "string"
+                },
+                "updated": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                },
+                "videoUrl": {
+                    "description": "VideoUrl and PreviewUrl are download URLs of VideoPath and ImagePath, they are filled in project info and list",
+                    "type": "string"
+                },
+                "voice": {
+                    "type": "string"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.LintWarning"
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handler.ProjectUpdate": {
+        },
+        "model.MediaProbe": {
             "type": "object",
-            "required": [
-                "name"
-            ],
             "properties": {
-                "name": {
+                "audio": {
+                    "$ref": "#/definitions/model.AudioStream"
+                },
+                "bitRate": {
+                    "type": "integer"
+                },
+                "container": {
+                    "description": "Container is format name reported by ffprobe, e.g. \"mov,mp4,m4a,3gp,3g2,mj2\"",
                     "type": "string"
+                },
+                "duration": {
+                    "description": "Duration in tenths of a second, as start and duration of audio parts",
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "video": {
+                    "$ref": "#/definitions/model.VideoStream"
                 }
             }
         },
-        "model.AudioPart": {
+        "model.Note": {
             "type": "object",
             "properties": {
-                "duration": {
-                    "type": "integer"
+                "created": {
+                    "type": "string"
                 },
-                "partId": {
+                "mentions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "noteId": {
                     "type": "string"
                 },
-                "path": {
+                "parentId": {
+                    "type": "string"
+                },
+                "partId": {
                     "type": "string"
                 },
                 "projectId": {
                     "type": "string"
                 },
-                "start": {
-                    "type": "integer"
+                "replies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Note"
+                    }
+                },
+                "resolved": {
+                    "type": "boolean"
+                },
+                "resolvedAt": {
+                    "type": "string"
+                },
+                "resolvedBy": {
+                    "type": "string"
                 },
                 "text": {
                     "type": "string"
+                },
+                "time": {
+                    "type": "integer"
+                },
+                "userId": {
+                    "type": "string"
                 }
             }
         },
-        "model.Comment": {
+        "model.NoteCreate": {
             "type": "object",
+            "required": [
+                "text"
+            ],
             "properties": {
-                "splitPoint": {
+                "mentions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "parentId": {
+                    "type": "string"
+                },
+                "partId": {
                     "type": "string"
                 },
                 "text": {
                     "type": "string"
+                },
+                "time": {
+                    "type": "string"
                 }
             }
         },
-        "model.Image": {
+        "model.NoteResolve": {
             "type": "object",
             "properties": {
-                "name": {
+                "resolved": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.PartStatusUpdate": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "status": {
                     "type": "string"
                 }
             }
@@ -744,17 +3503,210 @@ const docTemplate = `{
                         "$ref": "#/definitions/model.AudioPart"
                     }
                 },
+                "created": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "type": "string"
+                },
+                "hlsPath": {
+                    "type": "string"
+                },
+                "hlsUrl": {
+                    "type": "string"
+                },
+                "isTemplate": {
+                    "type": "boolean"
+                },
+                "lintConfig": {
+                    "$ref": "#/definitions/model.LintConfig"
+                },
+                "mediaType": {
+                    "type": "string"
+                },
                 "name": {
                     "type": "string"
                 },
+                "outputHlsPath": {
+                    "type": "string"
+                },
+                "outputHlsUrl": {
+                    "type": "string"
+                },
+                "outputPath": {
+                    "description": "OutputPath is described audio of the latest render",
+                    "type": "string"
+                },
+                "outputUrl": {
+                    "type": "string"
+                },
                 "path": {
                     "type": "string"
                 },
+                "previewPath": {
+                    "type": "string"
+                },
+                "previewUrl": {
+                    "type": "string"
+                },
+                "probe": {
+                    "$ref": "#/definitions/model.MediaProbe"
+                },
                 "projectId": {
                     "type": "string"
                 },
+                "proxyPath": {
+                    "description": "ProxyPath is low-bitrate mp4 editing proxy of video, it is played in browser instead of original\nwhich is kept as uploaded. HlsPath and OutputHlsPath are master playlists of uploaded\nmedia and of described audio made after render, they are made in background and may be empty",
+                    "type": "string"
+                },
+                "proxyUrl": {
+                    "description": "ProxyUrl, HlsUrl, OutputHlsUrl and ThumbnailsUrl are URLs of proxy, playlists and thumbnails index,\nthey are filled in project info",
+                    "type": "string"
+                },
+                "ssml": {
+                    "type": "boolean"
+                },
+                "status": {
+                    "description": "Status is filled only in project list, see ProjectStatus* constants",
+                    "type": "string"
+                },
+                "thumbnailsPath": {
+                    "description": "ThumbnailsPath is WebVTT index of thumbnail sprite sheets of video, it is made in background too",
+                    "type": "string"
+                },
+                "thumbnailsUrl": {
+                    "type": "string"
+                },
+                "updated": {
+                    "type": "string"
+                },
                 "userId": {
                     "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                },
+                "videoUrl": {
+                    "description": "VideoUrl and PreviewUrl are download URLs of VideoPath and ImagePath, they are filled in project info and list",
+                    "type": "string"
+                },
+                "voice": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ProjectList": {
+            "type": "object",
+            "properties": {
+                "nextCursor": {
+                    "type": "string"
+                },
+                "projects": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Project"
+                    }
+                }
+            }
+        },
+        "model.ReplacePreview": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "type": "string"
+                },
+                "before": {
+                    "type": "string"
+                },
+                "matches": {
+                    "type": "integer"
+                },
+                "partId": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ReplaceRequest": {
+            "type": "object",
+            "required": [
+                "find"
+            ],
+            "properties": {
+                "caseSensitive": {
+                    "type": "boolean"
+                },
+                "find": {
+                    "type": "string"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "replace": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SearchHit": {
+            "type": "object",
+            "properties": {
+                "partId": {
+                    "type": "string"
+                },
+                "previewUrl": {
+                    "type": "string"
+                },
+                "projectId": {
+                    "type": "string"
+                },
+                "projectName": {
+                    "type": "string"
+                },
+                "rank": {
+                    "type": "number"
+                },
+                "snippet": {
+                    "description": "Snippet is HTML escaped fragment of text where matches are wrapped in \u003cmark\u003e",
+                    "type": "string"
+                },
+                "start": {
+                    "description": "Start is in tenths of a second, Time is the same in hh:mm:ss.ms format",
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                },
+                "videoTime": {
+                    "description": "VideoTime is moment of original media where description is inserted, it is Start without durations\nof descriptions which go before",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.TranscriptSegment": {
+            "type": "object",
+            "properties": {
+                "duration": {
+                    "type": "integer"
+                },
+                "projectId": {
+                    "type": "string"
+                },
+                "segmentId": {
+                    "type": "string"
+                },
+                "speaker": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "string"
                 }
             }
         },
@@ -773,6 +3725,35 @@ const docTemplate = `{
                 }
             }
         },
+        "model.VideoStream": {
+            "type": "object",
+            "properties": {
+                "codec": {
+                    "type": "string"
+                },
+                "frameRate": {
+                    "type": "number"
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "width": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.VoiceSettings": {
+            "type": "object",
+            "properties": {
+                "ssml": {
+                    "type": "boolean"
+                },
+                "voice": {
+                    "description": "Voice is name of TTS voice, empty means default voice",
+                    "type": "string"
+                }
+            }
+        },
         "model.VoiceText": {
             "type": "object",
             "properties": {
@@ -780,6 +3761,35 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "waveform.Waveform": {
+            "type": "object",
+            "properties": {
+                "bits": {
+                    "type": "integer"
+                },
+                "channels": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "length": {
+                    "type": "integer"
+                },
+                "sample_rate": {
+                    "type": "integer"
+                },
+                "samples_per_pixel": {
+                    "type": "integer"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,195 @@
+package gc
+
+import (
+	"context"
+	"path"
+	"strings"
+	"time"
+
+	"tiflo/internal/repository"
+	"tiflo/model"
+	"tiflo/pkg/storage"
+	"tiflo/pkg/waveform"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+type Config struct {
+	// Grace is minimal age of unreferenced file, younger files may belong to a request which is still running
+	Grace time.Duration
+	// Interval of background collection, zero disables it
+	Interval time.Duration
+	// DryRun only reports files which would be deleted, it is on unless config turns it off
+	DryRun bool
+}
+
+func InitConfig(vp *viper.Viper) Config {
+	config := Config{
+		Grace:  24 * time.Hour,
+		DryRun: true,
+	}
+
+	if grace := vp.GetDuration("gc.grace"); grace > 0 {
+		config.Grace = grace
+	}
+	config.Interval = vp.GetDuration("gc.interval")
+	if vp.IsSet("gc.dryRun") {
+		config.DryRun = vp.GetBool("gc.dryRun")
+	}
+
+	return config
+}
+
+type Report struct {
+	DryRun         bool     `json:"dryRun"`
+	Scanned        int      `json:"scanned"`
+	Referenced     int      `json:"referenced"`
+	Deleted        []string `json:"deleted"`
+	ReclaimedBytes int64    `json:"reclaimedBytes"`
+}
+
+// Collector deletes media files which are referenced neither by projects nor by audio parts
+type Collector struct {
+	repo    repository.Repository
+	config  Config
+	storage storage.Storage
+	logger  *logrus.Entry
+}
+
+func NewCollector(logger *logrus.Logger, repo repository.Repository, config Config, storage storage.Storage) *Collector {
+	return &Collector{
+		repo:    repo,
+		config:  config,
+		storage: storage,
+		logger:  logger.WithField("component", "media-gc"),
+	}
+}
+
+// Run collects garbage every Interval until ctx is done
+func (c *Collector) Run(ctx context.Context) {
+	if c.config.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(c.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		report, err := c.Collect(ctx, c.config.DryRun)
+		if err != nil {
+			c.logger.Error("collect media: ", err)
+			continue
+		}
+
+		c.logger.Infof("scanned %d files, deleted %d, reclaimed %d bytes, dry run: %v",
+			report.Scanned, len(report.Deleted), report.ReclaimedBytes, report.DryRun)
+	}
+}
+
+// uploadDir returns directory of upload which object is chunk of
+func uploadDir(name string) (string, bool) {
+	if !strings.HasPrefix(name, model.UploadChunksDir) {
+		return "", false
+	}
+
+	return path.Dir(name), true
+}
+
+// previewIndexes maps directories of previews made of many files to name of index which project refers to
+var previewIndexes = map[string]string{
+	model.HLSDir:        model.HLSMasterPlaylist,
+	model.ThumbnailsDir: model.ThumbnailsIndex,
+}
+
+// previewDir returns directory of preview which object belongs to and name of index of preview
+func previewDir(name string) (string, string, bool) {
+	for prefix, index := range previewIndexes {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		id, _, found := strings.Cut(strings.TrimPrefix(name, prefix), "/")
+		if !found {
+			return "", "", false
+		}
+
+		return prefix + id, index, true
+	}
+
+	return "", "", false
+}
+
+// Collect deletes unreferenced objects of storage older than grace period.
+// Only top level objects, chunks of unfinished uploads, HLS ladders, thumbnails and cached waveforms are checked,
+// other nested ones are skipped.
+func (c *Collector) Collect(ctx context.Context, dryRun bool) (Report, error) {
+	report := Report{DryRun: dryRun, Deleted: []string{}}
+
+	// files are listed before references are loaded, so file saved with its reference in between is not lost
+	objects, err := c.storage.List(ctx, "")
+	if err != nil {
+		return report, err
+	}
+
+	referenced, err := c.repo.GetReferencedMedia(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	// chunks of unfinished upload are garbage only when its latest chunk is older than grace period,
+	// so grace must not be less than upload expiration. Previews are kept the same way while they are written.
+	latest := make(map[string]time.Time)
+	for _, object := range objects {
+		dir, ok := uploadDir(object.Name)
+		if !ok {
+			dir, _, ok = previewDir(object.Name)
+		}
+		if ok && object.Modified.After(latest[dir]) {
+			latest[dir] = object.Modified
+		}
+	}
+
+	deadline := time.Now().Add(-c.config.Grace)
+	for _, object := range objects {
+		name, modified := object.Name, object.Modified
+		if upload, ok := uploadDir(object.Name); ok {
+			modified = latest[upload]
+		} else if preview, index, ok := previewDir(object.Name); ok {
+			name, modified = preview+"/"+index, latest[preview]
+		} else if media, ok := waveform.CachedMedia(object.Name); ok {
+			// cached waveforms live while their media file is referenced
+			name = media
+		} else if strings.Contains(object.Name, "/") {
+			continue
+		}
+		report.Scanned++
+
+		if referenced[name] {
+			report.Referenced++
+			continue
+		}
+
+		if modified.After(deadline) {
+			continue
+		}
+
+		if !dryRun {
+			if err = c.storage.Delete(ctx, object.Name); err != nil {
+				c.logger.Errorf("remove media file %s: %s", object.Name, err)
+				continue
+			}
+		}
+
+		report.Deleted = append(report.Deleted, object.Name)
+		report.ReclaimedBytes += object.Size
+	}
+
+	return report, nil
+}
@@ -9,7 +9,8 @@ import (
 
 // VoiceText godoc
 // @Summary      Voice the given text
-// @Description  Voice the given text
+// @Description  Voice the given text with lexicon and voice settings of project. Project is not changed,
+// @Description  so If-Match is not required: voiced file is attached to project only by comment requests.
 // @Tags         Project
 // @Accept       json
 // @Produce      json
@@ -22,14 +23,21 @@ import (
 func (h *Handler) VoiceText(context *gin.Context) {
 	var textComment model.VoiceText
 
-	if err := context.BindJSON(&textComment); err != nil {
+	// project lexicon and voice settings are applied to text
+	project, err := model.GetProject(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err = context.BindJSON(&textComment); err != nil {
 		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err})
 		return
 	}
 
 	h.logger.Info("VoiceText Handler", textComment.Text)
 
-	path, err := h.pythonClient.VoiceTheText(context.Request.Context(), textComment.Text)
+	path, _, err := h.voiceText(context.Request.Context(), project, textComment.Text)
 	if err != nil {
 		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err})
 		return
@@ -40,55 +48,77 @@ func (h *Handler) VoiceText(context *gin.Context) {
 
 // ImageToText godoc
 // @Summary      Create tiflo comment
-// @Description  Create tiflo comment for given image
+// @Description  Create tiflo comment for image of project, project has to be made from image
 // @Tags         Comment
-// @Accept       json
 // @Produce      json
-// @Param        user  body  model.Image  true  "name of image(uuid)"
+// @Param        projectId  path  string  true  "Project Id"
+// @Param        If-Match  header  string  true  "Project version got from ETag"
 // @Success      200  {object}  map[string]any
 // @Failure      400  {object}  error
 // @Failure      401  {object}  error
+// @Failure      412  {object}  error
+// @Failure      423  {object}  map[string]any
 // @Failure      500  {object}  error
 // @Router       /api/projects/{projectId}/image/comment [post]
 func (h *Handler) ImageToText(context *gin.Context) {
-	var imagePath model.Image
-	h.logger.Info(imagePath)
+	project, err := model.GetProject(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-	if err := context.BindJSON(&imagePath); err != nil {
-		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err})
+	// only image of project is captioned, so media of other users can't be reached by its name
+	if project.MediaType != model.MediaTypeImage || project.ImagePath == "" {
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "в проекте нет изображения"})
 		return
 	}
 
-	projectIdStr := context.Param("projectId")
-	projectId, err := uuid.Parse(projectIdStr)
+	paths, cleanup, err := h.exposeFiles(context.Request.Context(), h.captionConfig.InputDir, []string{project.ImagePath})
 	if err != nil {
-		context.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	defer cleanup()
 
-	text, err := h.pythonClient.ImageToText(context.Request.Context(), "/home/vavasto/frontend/public/media/"+imagePath.Name)
+	text, err := h.pythonClient.ImageToText(context.Request.Context(), paths[0])
 	if err != nil {
 		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err})
 		return
 	}
 	h.logger.Info(text)
 
-	err = h.repo.SaveProjectAudio(context.Request.Context(), model.Project{
-		ProjectId: projectId,
-		UserId:    uuid.UUID{},
-		AudioParts: []model.AudioPart{{
-			PartId:    uuid.New(),
-			ProjectId: projectId,
-			Start:     0,
-			Duration:  0,
-			Text:      text,
-			Path:      "",
-		}},
+	// new comment replaces all audio parts of project, notes of replaced parts are anchored to it
+	comment := model.AudioPart{
+		PartId:    uuid.New(),
+		ProjectId: project.ProjectId,
+		Start:     0,
+		Duration:  0,
+		Text:      text,
+		Path:      "",
+	}
+	deleted := make([]uuid.UUID, 0, len(project.AudioParts))
+	moved := make([]model.NoteMove, 0, len(project.AudioParts))
+	for _, part := range project.AudioParts {
+		deleted = append(deleted, part.PartId)
+		moved = append(moved, model.NoteMove{From: part.PartId, To: comment.PartId})
+	}
+
+	version, err := h.repo.UpdateTimeline(context.Request.Context(), project.UserId, model.TimelineUpdate{
+		ProjectId:    project.ProjectId,
+		Version:      project.Version,
+		FencingToken: model.GetLockToken(context),
+		Deleted:      deleted,
+		Moved:        moved,
+		Updated:      []model.AudioPart{comment},
 	})
 	if err != nil {
-		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err})
+		context.AbortWithStatusJSON(repoErrorStatus(err), gin.H{"error": err})
 		return
 	}
 
-	context.JSON(http.StatusOK, text)
+	context.Header("ETag", projectETag(version))
+	context.JSON(http.StatusOK, gin.H{
+		"text":     text,
+		"warnings": h.linter.LintPart(h.projectLintConfig(project), comment),
+	})
 }
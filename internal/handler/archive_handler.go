@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"tiflo/model"
+	"tiflo/pkg/archive"
+)
+
+// ExportProject godoc
+// @Summary      Export project
+// @Description  Download archive with project, audio parts, lexicon, media files and manifest with checksums
+// @Tags         Project
+// @Produce      application/zip
+// @Produce      application/x-tar
+// @Param        projectId  path  string  true  "Project Id"
+// @Param        format  query  string  false  "zip (default) or tar"
+// @Param        force  query  bool  false  "Export even if some descriptions are not approved"
+// @Success      200  {file}  file
+// @Header       200  {int}  X-Not-Approved  "Number of descriptions which are not approved, set on forced export"
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      409  {object}  model.DeliveryReadiness
+// @Failure      500  {object}  error
+// @Router       /api/projects/{projectId}/export [get]
+func (h *Handler) ExportProject(context *gin.Context) {
+	project, err := model.GetProject(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	format := context.DefaultQuery("format", archive.FormatZip)
+	contentType := "application/zip"
+	switch format {
+	case archive.FormatZip:
+	case archive.FormatTar:
+		contentType = "application/x-tar"
+	default:
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": archive.UnknownFormat.Error()})
+		return
+	}
+
+	if _, ok := h.checkDeliveryReadiness(context, project); !ok {
+		return
+	}
+
+	entries, err := h.repo.GetLexicon(context.Request.Context(), project.UserId, &project.ProjectId)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	// user lexicon stays with user, only project entries are exported
+	lexicon := make([]model.LexiconEntry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.ProjectId != nil {
+			lexicon = append(lexicon, entry)
+		}
+	}
+
+	context.Header("Content-Type", contentType)
+	context.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, project.ProjectId, format))
+	context.Status(http.StatusOK)
+
+	// archive is streamed, so error can't be reported with status code after the first written byte
+	if err = h.archiver.Export(context.Request.Context(), context.Writer, format, project, lexicon); err != nil {
+		h.logger.Error("export project: ", err)
+		context.Abort()
+	}
+}
+
+// ImportProject godoc
+// @Summary      Import project
+// @Description  Create project from archive made by export, project and media files get new ids
+// @Tags         Project
+// @Accept       mpfd
+// @Produce      json
+// @Param        file formData file true "Archive, .zip or .tar"
+// @Success      200  {object}  model.Project
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      422  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/projects/import [post]
+func (h *Handler) ImportProject(context *gin.Context) {
+	userId, err := model.GetUserId(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+		return
+	}
+
+	fileHeader, err := context.FormFile("file")
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "файл архива не передан"})
+		return
+	}
+
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(fileHeader.Filename)), ".")
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	project, lexicon, err := h.archiver.Import(context.Request.Context(), file, fileHeader.Size, format, userId)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, archive.UnknownFormat) || errors.Is(err, archive.UnsupportedVersion) ||
+			errors.Is(err, archive.BrokenArchive) {
+			status = http.StatusBadRequest
+		}
+		context.AbortWithStatusJSON(status, gin.H{"message": err.Error()})
+		return
+	}
+
+	// archive may be crafted, so its media are checked like uploaded ones
+	if err = h.checkImportedMedia(context.Request.Context(), &project); err != nil {
+		h.deleteImportedMedia(context.Request.Context(), project)
+		context.AbortWithStatusJSON(repoErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+
+	project, err = h.repo.ImportProject(context.Request.Context(), project, lexicon)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	// previews are not exported, they are made again
+	go h.makePreviews(project)
+
+	h.fillMediaUrls(&project)
+	context.JSON(http.StatusOK, project)
+}
+
+// checkImportedMedia probes media of imported project against media limits, probe of main media is saved to project.
+// Audio track and preview are checked as audio and image.
+func (h *Handler) checkImportedMedia(ctx context.Context, project *model.Project) error {
+	switch project.MediaType {
+	case model.MediaTypeVideo, model.MediaTypeAudio, model.MediaTypeImage:
+		if project.VideoPath == "" {
+			return fmt.Errorf("%w: в архиве нет медиафайла проекта", model.InvalidMedia)
+		}
+	case model.MediaTypeNone:
+		if project.VideoPath != "" || project.AudioPath != "" || project.ImagePath != "" {
+			return fmt.Errorf("%w: не указан тип медиа проекта", model.InvalidMedia)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: неизвестный тип медиа %s", model.InvalidMedia, project.MediaType)
+	}
+
+	checks := []struct {
+		name      string
+		mediaType string
+	}{
+		{project.VideoPath, project.MediaType},
+		{project.AudioPath, model.MediaTypeAudio},
+		{project.ImagePath, model.MediaTypeImage},
+	}
+	for i, check := range checks {
+		if check.name == "" {
+			continue
+		}
+
+		probe, err := h.mediaService.Probe(ctx, check.name)
+		if err != nil {
+			return err
+		}
+		if err = h.mediaLimits.Check(probe, check.mediaType); err != nil {
+			return err
+		}
+
+		if i == 0 {
+			project.Probe = &probe
+		}
+	}
+
+	return nil
+}
+
+// deleteImportedMedia deletes files of project which is not imported
+func (h *Handler) deleteImportedMedia(ctx context.Context, project model.Project) {
+	names := map[string]bool{project.VideoPath: true, project.AudioPath: true, project.ImagePath: true}
+	for _, part := range project.AudioParts {
+		names[part.Path] = true
+	}
+
+	for name := range names {
+		if name == "" {
+			continue
+		}
+		if err := h.storage.Delete(ctx, name); err != nil {
+			h.logger.Error(err)
+		}
+	}
+}
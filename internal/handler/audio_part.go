@@ -3,6 +3,7 @@ package handler
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"math"
 	"net/http"
 	"sort"
 	"tiflo/model"
@@ -15,16 +16,20 @@ import (
 // @Produce      json
 // @Param        projectId    path  string  true  "Project Id"
 // @Param        audioPartId  path  string  true  "Audio part Id"
+// @Param        If-Match  header  string  true  "Project version got from ETag"
 // @Success      200  {object}  map[string]any
 // @Failure      400  {object}  error
 // @Failure      401  {object}  error
+// @Failure      404  {object}  error
+// @Failure      409  {object}  error
+// @Failure      412  {object}  error
+// @Failure      423  {object}  map[string]any
 // @Failure      500  {object}  error
 // @Router       /api/projects/{projectId}/audio-part/{audioPartId} [delete]
 func (h *Handler) DeleteAudioPart(context *gin.Context) {
-	projectIdStr := context.Param("projectId")
-	projectId, err := uuid.Parse(projectIdStr)
+	project, err := model.GetProject(context)
 	if err != nil {
-		context.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
 
@@ -35,80 +40,73 @@ func (h *Handler) DeleteAudioPart(context *gin.Context) {
 		return
 	}
 
-	userId, err := model.GetUserId(context)
-	if err != nil {
-		context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+	sort.SliceStable(project.AudioParts, func(i, j int) bool {
+		return project.AudioParts[i].Start < project.AudioParts[j].Start
+	})
+
+	i := -1
+	for j, part := range project.AudioParts {
+		if part.PartId == audioPartId {
+			i = j
+			break
+		}
+	}
+	if i == -1 {
+		context.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": model.NotFound.Error()})
+		return
+	}
+	// deleted part is replaced by merging its neighbours, so the first and the last parts can't be deleted
+	if i == 0 || i+1 == len(project.AudioParts) {
+		context.AbortWithStatusJSON(http.StatusConflict, gin.H{"message": "первый и последний фрагменты нельзя удалить"})
 		return
 	}
 
-	project, err := h.repo.GetProject(context.Request.Context(), model.Project{ProjectId: projectId, UserId: userId})
+	v := project.AudioParts[i]
+	partsToConcat := []model.AudioPart{project.AudioParts[i-1], project.AudioParts[i+1]}
+	h.logger.Info("partsToConcat:", partsToConcat)
+
+	path, err := h.mediaService.ConcatAudio(context.Request.Context(), partsToConcat)
 	if err != nil {
 		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
 
-	sort.SliceStable(project.AudioParts, func(i, j int) bool {
-		return project.AudioParts[i].Start < project.AudioParts[j].Start
-	})
+	concatedPart := model.AudioPart{
+		PartId:    project.AudioParts[i-1].PartId,
+		ProjectId: project.ProjectId,
+		Start:     project.AudioParts[i-1].Start,
+		Duration:  project.AudioParts[i-1].Duration + project.AudioParts[i+1].Duration,
+		Text:      "",
+		Path:      path,
+	}
 
-	partsToConcat := make([]model.AudioPart, 0, 2)
-	for i, v := range project.AudioParts {
-		if v.PartId == audioPartId && i != 0 {
-			partsToConcat = append(partsToConcat, project.AudioParts[i-1], project.AudioParts[i+1])
-			h.logger.Info("partsToConcat:", partsToConcat)
-
-			path, err := h.mediaService.ConcatAudio(partsToConcat)
-			if err != nil {
-				context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
-				return
-			}
-
-			concatedPart := model.AudioPart{
-				PartId:    project.AudioParts[i-1].PartId,
-				ProjectId: projectId,
-				Start:     project.AudioParts[i-1].Start,
-				Duration:  project.AudioParts[i-1].Duration + project.AudioParts[i+1].Duration,
-				Text:      "",
-				Path:      path,
-			}
-
-			_, err = h.repo.DeleteAudioPart(context.Request.Context(), project.AudioParts[i+1])
-			if err != nil {
-				context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
-				return
-			}
-
-			_, err = h.repo.DeleteAudioPart(context.Request.Context(), v)
-			if err != nil {
-				context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
-				return
-			}
-
-			audioPartsAfterSplitPoint, err := h.repo.GetAudioPartsAfterSplitPoint(context.Request.Context(), v.Start, projectId)
-			if err != nil {
-				h.logger.Error(err)
-				context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
-				return
-			}
-
-			for j, _ := range audioPartsAfterSplitPoint {
-				audioPartsAfterSplitPoint[j].Start -= v.Duration
-			}
-
-			audioPartsAfterSplitPoint = append(audioPartsAfterSplitPoint, concatedPart)
-			for _, part := range audioPartsAfterSplitPoint {
-				err = h.repo.UpdateAudioPart(context.Request.Context(), part)
-				if err != nil {
-					h.logger.Error(err)
-					context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
-					return
-				}
-			}
+	// parts which go after deleted one and the next part, which is merged with previous one
+	audioPartsAfterSplitPoint := make([]model.AudioPart, 0, len(project.AudioParts)-i)
+	for _, part := range project.AudioParts[i+2:] {
+		part.Start -= v.Duration
+		audioPartsAfterSplitPoint = append(audioPartsAfterSplitPoint, part)
+	}
 
-			break
-		}
+	audioPartsAfterSplitPoint = append(audioPartsAfterSplitPoint, concatedPart)
+	version, err := h.repo.UpdateTimeline(context.Request.Context(), project.UserId, model.TimelineUpdate{
+		ProjectId:    project.ProjectId,
+		Version:      project.Version,
+		FencingToken: model.GetLockToken(context),
+		Deleted:      []uuid.UUID{project.AudioParts[i+1].PartId, v.PartId},
+		Updated:      audioPartsAfterSplitPoint,
+		// notes of the next part follow its audio into merged part, notes of deleted description stay where it was
+		Moved: []model.NoteMove{
+			{From: project.AudioParts[i+1].PartId, To: concatedPart.PartId, Shift: -v.Duration, Until: math.MaxInt64},
+			{From: v.PartId, To: concatedPart.PartId, Until: v.Start},
+		},
+	})
+	if err != nil {
+		h.logger.Error(err)
+		context.AbortWithStatusJSON(repoErrorStatus(err), gin.H{"message": err.Error()})
+		return
 	}
 
+	context.Header("ETag", projectETag(version))
 	context.JSON(http.StatusOK, gin.H{"message": "successfully deleted"})
 }
 
@@ -120,17 +118,20 @@ func (h *Handler) DeleteAudioPart(context *gin.Context) {
 // @Produce      json
 // @Param        projectId  path  string  true  "Project Id"
 // @Param        audioPartId  path  string  true  "Audio part Id"
+// @Param        If-Match  header  string  true  "Project version got from ETag"
 // @Param        comment  body  model.Comment  true  "New text for comment"
 // @Success      200  {object}  map[string]any
 // @Failure      400  {object}  error
 // @Failure      401  {object}  error
+// @Failure      404  {object}  error
+// @Failure      412  {object}  error
+// @Failure      423  {object}  map[string]any
 // @Failure      500  {object}  error
 // @Router       /api/projects/{projectId}/audio-part/{audioPartId} [put]
 func (h *Handler) ChangeCommentText(context *gin.Context) {
-	projectIdStr := context.Param("projectId")
-	projectId, err := uuid.Parse(projectIdStr)
+	project, err := model.GetProject(context)
 	if err != nil {
-		context.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
 
@@ -147,26 +148,21 @@ func (h *Handler) ChangeCommentText(context *gin.Context) {
 		return
 	}
 
-	// delete all audio part and delete its duration from parts which go after it
-	oldPart, err := h.repo.DeleteAudioPart(context.Request.Context(), model.AudioPart{PartId: audioPartId, ProjectId: projectId})
-	if err != nil {
-		context.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
-		return
+	var oldPart model.AudioPart
+	var found bool
+	for _, part := range project.AudioParts {
+		if part.PartId == audioPartId {
+			oldPart, found = part, true
+			break
+		}
 	}
-
-	audioPartsAfterSplitPoint, err := h.repo.GetAudioPartsAfterSplitPoint(context.Request.Context(), oldPart.Start, projectId)
-	if err != nil {
-		h.logger.Error(err)
-		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+	if !found {
+		context.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": model.NotFound.Error()})
 		return
 	}
 
-	for i, _ := range audioPartsAfterSplitPoint {
-		audioPartsAfterSplitPoint[i].Start -= oldPart.Duration
-	}
-
 	// voice new text
-	path, err := h.pythonClient.VoiceTheText(context.Request.Context(), comment.Text)
+	path, voiceInput, err := h.voiceText(context.Request.Context(), project, comment.Text)
 	if err != nil {
 		h.logger.Error(err)
 		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
@@ -174,35 +170,51 @@ func (h *Handler) ChangeCommentText(context *gin.Context) {
 	}
 
 	// get duration of new text
-	_, durationInt, err := h.mediaService.GetAudioDurationWav(path)
+	_, durationInt, err := h.mediaService.GetAudioDurationWav(context.Request.Context(), path)
 	if err != nil {
 		h.logger.Error(err)
 		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
 
-	// add new part duration to parts which go after
-	for i, _ := range audioPartsAfterSplitPoint {
-		audioPartsAfterSplitPoint[i].Start += durationInt
+	// replace old part duration with new one in parts which go after it
+	audioPartsAfterSplitPoint := make([]model.AudioPart, 0, len(project.AudioParts))
+	for _, part := range project.AudioParts {
+		if part.Start > oldPart.Start {
+			part.Start += durationInt - oldPart.Duration
+			audioPartsAfterSplitPoint = append(audioPartsAfterSplitPoint, part)
+		}
 	}
 
 	audioPartsAfterSplitPoint = append(audioPartsAfterSplitPoint, model.AudioPart{
-		PartId:    oldPart.PartId,
-		ProjectId: oldPart.ProjectId,
-		Start:     oldPart.Start,
-		Duration:  durationInt,
-		Text:      comment.Text,
-		Path:      path,
+		PartId:     oldPart.PartId,
+		ProjectId:  oldPart.ProjectId,
+		Start:      oldPart.Start,
+		Duration:   durationInt,
+		Text:       comment.Text,
+		Path:       path,
+		VoiceInput: voiceInput,
+		Voice:      project.Voice,
+		Status:     model.PartStatusDraft,
 	})
 
-	for _, part := range audioPartsAfterSplitPoint {
-		err = h.repo.UpdateAudioPart(context.Request.Context(), part)
-		if err != nil {
-			h.logger.Error(err)
-			context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
-			return
-		}
+	version, err := h.repo.UpdateTimeline(context.Request.Context(), project.UserId, model.TimelineUpdate{
+		ProjectId:    project.ProjectId,
+		Version:      project.Version,
+		FencingToken: model.GetLockToken(context),
+		Updated:      audioPartsAfterSplitPoint,
+	})
+	if err != nil {
+		h.logger.Error(err)
+		context.AbortWithStatusJSON(repoErrorStatus(err), gin.H{"message": err.Error()})
+		return
 	}
 
-	context.JSON(http.StatusOK, gin.H{"message": "successfully changed"})
+	changedPart := audioPartsAfterSplitPoint[len(audioPartsAfterSplitPoint)-1]
+
+	context.Header("ETag", projectETag(version))
+	context.JSON(http.StatusOK, gin.H{
+		"message":  "successfully changed",
+		"warnings": h.linter.LintPart(h.projectLintConfig(project), changedPart),
+	})
 }
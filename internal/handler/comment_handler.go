@@ -1,36 +1,240 @@
 package handler
 
 import (
+	"context"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"math"
 	"net/http"
+	"sort"
+	"strings"
 	"tiflo/model"
+	"time"
+	"unicode/utf8"
 )
 
+type CaptionConfig struct {
+	// Frames is number of frames sampled around commented moment
+	Frames int
+	// Span is time which frames are sampled over, commented moment is in its middle
+	Span time.Duration
+	// InputDir is directory where image2text and OCR services read images from
+	InputDir string
+	// MinTextConfidence is confidence which on-screen text has to be recognised with to get into description
+	MinTextConfidence float32
+}
+
+func initCaptionConfig(vp *viper.Viper) CaptionConfig {
+	config := CaptionConfig{
+		Frames:            5,
+		Span:              2 * time.Second,
+		InputDir:          PathForMedia,
+		MinTextConfidence: 0.5,
+	}
+
+	if frames := vp.GetInt("python.image2text.frames"); frames > 0 {
+		config.Frames = frames
+	}
+	if span := vp.GetDuration("python.image2text.span"); span > 0 {
+		config.Span = span
+	}
+	if inputDir := vp.GetString("python.image2text.inputDir"); inputDir != "" {
+		config.InputDir = inputDir
+	}
+	if vp.IsSet("python.ocr.minConfidence") {
+		config.MinTextConfidence = float32(vp.GetFloat64("python.ocr.minConfidence"))
+	}
+
+	return config
+}
+
+// frameTimes returns times of frames evenly sampled over span around moment, all times are in tenths of a second.
+// Frames outside of video are skipped, zero duration means it is unknown.
+func (c CaptionConfig) frameTimes(moment int64, duration int64) []int64 {
+	if c.Frames == 1 {
+		return []int64{moment}
+	}
+
+	span := c.Span.Milliseconds() / 100
+	times := make([]int64, 0, c.Frames)
+	for i := 0; i < c.Frames; i++ {
+		t := moment - span/2 + span*int64(i)/int64(c.Frames-1)
+		if t < 0 || (duration > 0 && t >= duration) {
+			continue
+		}
+		if len(times) > 0 && times[len(times)-1] == t {
+			continue
+		}
+		times = append(times, t)
+	}
+
+	if len(times) == 0 {
+		times = append(times, moment)
+	}
+
+	return times
+}
+
+// neighbourTexts returns descriptions of the closest described parts before and after split point
+func neighbourTexts(parts []model.AudioPart, splitPoint int64) (string, string) {
+	var previous, next *model.AudioPart
+	for i := range parts {
+		part := &parts[i]
+		if part.Text == "" {
+			continue
+		}
+
+		if part.Start < splitPoint && (previous == nil || part.Start > previous.Start) {
+			previous = part
+		}
+		if part.Start >= splitPoint && (next == nil || part.Start < next.Start) {
+			next = part
+		}
+	}
+
+	var previousText, nextText string
+	if previous != nil {
+		previousText = previous.Text
+	}
+	if next != nil {
+		nextText = next.Text
+	}
+
+	return previousText, nextText
+}
+
+// mergeOnScreenText appends on-screen text which description does not mention yet, blocks are read
+// from top to bottom and from left to right, blocks recognised with low confidence are skipped
+func mergeOnScreenText(description string, blocks []model.TextBlock, minConfidence float32) string {
+	sort.SliceStable(blocks, func(i, j int) bool {
+		if blocks[i].Y != blocks[j].Y {
+			return blocks[i].Y < blocks[j].Y
+		}
+		return blocks[i].X < blocks[j].X
+	})
+
+	mentioned := strings.ToLower(description)
+	texts := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		text := strings.Join(strings.Fields(block.Text), " ")
+		if text == "" || block.Confidence < minConfidence || strings.Contains(mentioned, strings.ToLower(text)) {
+			continue
+		}
+
+		texts = append(texts, text)
+		mentioned += "\n" + strings.ToLower(text)
+	}
+
+	if len(texts) == 0 {
+		return description
+	}
+
+	onScreen := "Надпись: «" + strings.Join(texts, "», «") + "»."
+	if strings.TrimSpace(description) == "" {
+		return onScreen
+	}
+
+	description = strings.TrimSpace(description)
+	if last, _ := utf8.DecodeLastRuneInString(description); !strings.ContainsRune(".!?…", last) {
+		description += "."
+	}
+
+	return description + " " + onScreen
+}
+
+// captionMoment captions frames sampled around moment of project video together with neighbouring descriptions,
+// on-screen text of frame of the moment itself is added to caption
+func (h *Handler) captionMoment(ctx context.Context, project model.Project, moment int64, splitPoint int64) (string, error) {
+	var duration int64
+	if project.Probe != nil {
+		duration = project.Probe.Duration
+	}
+
+	times := h.captionConfig.frameTimes(moment, duration)
+	timestamps := make([]string, 0, len(times))
+	for _, t := range times {
+		timestamps = append(timestamps, h.mediaService.ConvertTimeToString(t))
+	}
+
+	frameNames, err := h.mediaService.ExtractFrames(ctx, project.VideoPath, timestamps)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		for _, frameName := range frameNames {
+			if err := h.storage.Delete(ctx, frameName); err != nil {
+				h.logger.Error(err)
+			}
+		}
+	}()
+
+	paths, cleanup, err := h.exposeFiles(ctx, h.captionConfig.InputDir, frameNames)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	var frameContext model.FrameContext
+	frameContext.PreviousText, frameContext.NextText = neighbourTexts(project.AudioParts, splitPoint)
+	closest := 0
+	for i, path := range paths {
+		frameContext.Frames = append(frameContext.Frames, model.CaptionFrame{Path: path, Offset: times[i] - moment})
+		if abs(times[i]-moment) < abs(times[closest]-moment) {
+			closest = i
+		}
+	}
+
+	caption, err := h.pythonClient.FrameContextToText(ctx, frameContext)
+	if err != nil {
+		return "", err
+	}
+
+	// description is still useful without on-screen text, so OCR failure is not reported to user
+	blocks, err := h.pythonClient.RecognizeText(ctx, paths[closest])
+	if err != nil {
+		h.logger.Error("recognize on-screen text: ", err)
+		return caption, nil
+	}
+
+	return mergeOnScreenText(caption, blocks, h.captionConfig.MinTextConfidence), nil
+}
+
+func abs(x int64) int64 {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
+
 // CreateComment godoc
 // @Summary      Create comment on video
-// @Description  Create comment on video using split point
+// @Description  Create comment on video using split point, frames around video time are captioned together
+// @Description  with descriptions of neighbouring audio parts, recognised on-screen text is appended
 // @Tags         Comment
 // @Accept       json
 // @Produce      json
 // @Param        projectId  path  string  true  "Project Id"
+// @Param        If-Match  header  string  true  "Project version got from ETag"
 // @Param        comment  body  model.Comment  true  "Split point"
-// @Success      200  {object}  model.Project
+// @Success      200  {object}  model.LintedProject
 // @Failure      400  {object}  error
 // @Failure      401  {object}  error
+// @Failure      412  {object}  error
+// @Failure      423  {object}  map[string]any
 // @Failure      500  {object}  error
 // @Router       /api/projects/{projectId}/video/comment [post]
 func (h *Handler) CreateComment(context *gin.Context) {
-	projectIdStr := context.Param("projectId")
-	projectId, err := uuid.Parse(projectIdStr)
+	project, err := model.GetProject(context)
 	if err != nil {
-		context.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
+	projectId := project.ProjectId
 
-	userId, err := model.GetUserId(context)
-	if err != nil {
-		context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+	if project.MediaType != model.MediaTypeVideo {
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "в проекте нет видео"})
 		return
 	}
 
@@ -40,26 +244,17 @@ func (h *Handler) CreateComment(context *gin.Context) {
 		return
 	}
 
-	project, err := h.repo.GetProject(context.Request.Context(), model.Project{ProjectId: projectId, UserId: userId})
-	if err != nil {
-		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
-		return
-	}
-
-	frameName, err := h.mediaService.ExtractFrame(PathForMedia+project.VideoPath, comment.VideoTime)
-	if err != nil {
-		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
-		return
-	}
+	splitPoint := h.mediaService.ConvertTimeFromString(comment.SplitPoint)
+	moment := h.mediaService.ConvertTimeFromString(comment.VideoTime)
 
-	text, err := h.pythonClient.ImageToText(context.Request.Context(), "/home/vavasto/frontend/public/media/"+frameName)
+	text, err := h.captionMoment(context.Request.Context(), project, moment, splitPoint)
 	if err != nil {
 		h.logger.Error(err)
 		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
 
-	path, err := h.pythonClient.VoiceTheText(context.Request.Context(), text)
+	path, voiceInput, err := h.voiceText(context.Request.Context(), project, text)
 	if err != nil {
 		h.logger.Error(err)
 		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
@@ -68,15 +263,13 @@ func (h *Handler) CreateComment(context *gin.Context) {
 
 	// get duration
 
-	duration, durationInt, err := h.mediaService.GetAudioDurationWav(path)
+	duration, durationInt, err := h.mediaService.GetAudioDurationWav(context.Request.Context(), path)
 	if err != nil {
 		h.logger.Error(err)
 		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
 
-	splitPoint := h.mediaService.ConvertTimeFromString(comment.SplitPoint)
-
 	audioPartToSplit, err := h.repo.GetAudioPartBySplitPoint(context.Request.Context(), splitPoint, projectId)
 	if err != nil {
 		h.logger.Error(err)
@@ -84,28 +277,32 @@ func (h *Handler) CreateComment(context *gin.Context) {
 		return
 	}
 
-	splittedParts, err := h.mediaService.SplitAudio(audioPartToSplit, comment.SplitPoint, duration)
+	splittedParts, err := h.mediaService.SplitAudio(context.Request.Context(), audioPartToSplit, comment.SplitPoint, duration)
 	if err != nil {
 		h.logger.Error(err)
 		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
 
-	_, err = h.repo.DeleteAudioPart(context.Request.Context(), model.AudioPart{PartId: audioPartToSplit.PartId, ProjectId: projectId})
-	if err != nil {
-		h.logger.Error(err)
-		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
-		return
+	// notes of split part are kept on the half they were left on, notes of the second half move with it
+	firstHalf, secondHalf := splittedParts[0], splittedParts[1]
+	moved := []model.NoteMove{
+		{From: audioPartToSplit.PartId, To: secondHalf.PartId, Since: splitPoint, Shift: secondHalf.Start - splitPoint,
+			Until: math.MaxInt64},
+		{From: audioPartToSplit.PartId, To: firstHalf.PartId, Until: math.MaxInt64},
 	}
 
-	splittedParts = append(splittedParts, model.AudioPart{
-		PartId:    uuid.New(),
-		ProjectId: projectId,
-		Start:     splitPoint,
-		Duration:  durationInt,
-		Text:      text,
-		Path:      path,
-	})
+	commentPart := model.AudioPart{
+		PartId:     uuid.New(),
+		ProjectId:  projectId,
+		Start:      splitPoint,
+		Duration:   durationInt,
+		Text:       text,
+		Path:       path,
+		VoiceInput: voiceInput,
+		Voice:      project.Voice,
+	}
+	splittedParts = append(splittedParts, commentPart)
 
 	audioPartsAfterSplitPoint, err := h.repo.GetAudioPartsAfterSplitPoint(context.Request.Context(), splitPoint, projectId)
 	if err != nil {
@@ -120,20 +317,30 @@ func (h *Handler) CreateComment(context *gin.Context) {
 
 	audioPartsAfterSplitPoint = append(audioPartsAfterSplitPoint, splittedParts...)
 
-	for _, part := range audioPartsAfterSplitPoint {
-		err = h.repo.UpdateAudioPart(context.Request.Context(), part)
-		if err != nil {
-			h.logger.Error(err)
-			context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
-			return
-		}
+	version, err := h.repo.UpdateTimeline(context.Request.Context(), project.UserId, model.TimelineUpdate{
+		ProjectId:    projectId,
+		Version:      project.Version,
+		FencingToken: model.GetLockToken(context),
+		Deleted:      []uuid.UUID{audioPartToSplit.PartId},
+		Updated:      audioPartsAfterSplitPoint,
+		Moved:        moved,
+	})
+	if err != nil {
+		h.logger.Error(err)
+		context.AbortWithStatusJSON(repoErrorStatus(err), gin.H{"message": err.Error()})
+		return
 	}
 
-	updatedProject, err := h.repo.GetProject(context.Request.Context(), model.Project{ProjectId: projectId, UserId: userId})
+	updatedProject, err := h.repo.GetProject(context.Request.Context(), model.Project{ProjectId: projectId, UserId: project.UserId})
 	if err != nil {
 		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
 
-	context.JSON(http.StatusOK, updatedProject)
+	h.fillMediaUrls(&updatedProject)
+	context.Header("ETag", projectETag(version))
+	context.JSON(http.StatusOK, model.LintedProject{
+		Project:  updatedProject,
+		Warnings: h.linter.LintPart(h.projectLintConfig(project), commentPart),
+	})
 }
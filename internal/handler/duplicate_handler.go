@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"path"
+	"path/filepath"
+	"tiflo/model"
+	"tiflo/pkg/storage"
+)
+
+type ProjectDuplicate struct {
+	// Name of new project, by default name of source project with suffix
+	Name string `json:"name"`
+	// CopyMedia makes copies of media files, otherwise both projects use the same files
+	CopyMedia bool `json:"copyMedia"`
+}
+
+type ProjectTemplate struct {
+	IsTemplate bool `json:"isTemplate"`
+}
+
+// copyMediaFile copies file of storage under new name with the same extension
+func (h *Handler) copyMediaFile(ctx context.Context, name string) (string, error) {
+	newName := uuid.New().String() + filepath.Ext(name)
+	if err := storage.Copy(ctx, h.storage, name, newName); err != nil {
+		return "", err
+	}
+
+	return newName, nil
+}
+
+// copyMediaDir copies directory of preview, e.g. HLS ladder, and returns index of copy
+func (h *Handler) copyMediaDir(ctx context.Context, index string) (string, error) {
+	if index == "" {
+		return "", nil
+	}
+
+	dir := path.Dir(index)
+	newDir := path.Join(path.Dir(dir), uuid.New().String())
+	if err := storage.CopyDir(ctx, h.storage, dir, newDir); err != nil {
+		return "", err
+	}
+
+	return newDir + "/" + path.Base(index), nil
+}
+
+// copyMediaFiles copies every file of project once and renames them in project
+func (h *Handler) copyMediaFiles(ctx context.Context, project *model.Project) error {
+	copies := make(map[string]string)
+	rename := func(name string) (string, error) {
+		if name == "" {
+			return "", nil
+		}
+		if newName, ok := copies[name]; ok {
+			return newName, nil
+		}
+
+		newName, err := h.copyMediaFile(ctx, name)
+		if err != nil {
+			return "", err
+		}
+		copies[name] = newName
+
+		return newName, nil
+	}
+
+	var err error
+	if project.VideoPath, err = rename(project.VideoPath); err != nil {
+		return err
+	}
+	if project.AudioPath, err = rename(project.AudioPath); err != nil {
+		return err
+	}
+	if project.ImagePath, err = rename(project.ImagePath); err != nil {
+		return err
+	}
+
+	if project.ProxyPath, err = rename(project.ProxyPath); err != nil {
+		return err
+	}
+	if project.OutputPath, err = rename(project.OutputPath); err != nil {
+		return err
+	}
+	if project.HlsPath, err = h.copyMediaDir(ctx, project.HlsPath); err != nil {
+		return err
+	}
+	if project.OutputHlsPath, err = h.copyMediaDir(ctx, project.OutputHlsPath); err != nil {
+		return err
+	}
+	if project.ThumbnailsPath, err = h.copyMediaDir(ctx, project.ThumbnailsPath); err != nil {
+		return err
+	}
+
+	for i := range project.AudioParts {
+		if project.AudioParts[i].Path, err = rename(project.AudioParts[i].Path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DuplicateProject godoc
+// @Summary      Duplicate project
+// @Description  Deep copy of project with its audio parts and lexicon, media files are copied on request.
+// @Description  Source project is not changed, If-Match makes sure that the version seen by client is copied.
+// @Tags         Project
+// @Accept       json
+// @Produce      json
+// @Param        projectId  path  string  true  "Project Id"
+// @Param        If-Match  header  string  true  "Project version got from ETag"
+// @Param        duplicate  body  ProjectDuplicate  false  "Name of new project and whether to copy media"
+// @Success      200  {object}  model.Project
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      412  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/projects/{projectId}/duplicate [post]
+func (h *Handler) DuplicateProject(context *gin.Context) {
+	project, err := model.GetProject(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	var request ProjectDuplicate
+	if context.Request.ContentLength != 0 {
+		if err = context.BindJSON(&request); err != nil {
+			context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "неверный формат данных"})
+			return
+		}
+	}
+
+	sourceId := project.ProjectId
+	duplicate := project
+	duplicate.ProjectId = uuid.New()
+	duplicate.IsTemplate = false
+	duplicate.Name = request.Name
+	if duplicate.Name == "" {
+		duplicate.Name = project.Name + " (копия)"
+	}
+
+	duplicate.AudioParts = make([]model.AudioPart, 0, len(project.AudioParts))
+	for _, part := range project.AudioParts {
+		part.PartId = uuid.New()
+		part.ProjectId = duplicate.ProjectId
+		duplicate.AudioParts = append(duplicate.AudioParts, part)
+	}
+
+	if request.CopyMedia {
+		if err = h.copyMediaFiles(context.Request.Context(), &duplicate); err != nil {
+			h.logger.Error(err)
+			context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "не удалось скопировать медиафайлы"})
+			return
+		}
+	}
+
+	duplicate, err = h.repo.DuplicateProject(context.Request.Context(), sourceId, duplicate)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	h.fillMediaUrls(&duplicate)
+	context.JSON(http.StatusOK, duplicate)
+}
+
+// SetProjectTemplate godoc
+// @Summary      Mark project as template
+// @Description  Template keeps voice, lint rules and lexicon which projects created from it inherit
+// @Tags         Project
+// @Accept       json
+// @Produce      json
+// @Param        projectId  path  string  true  "Project Id"
+// @Param        If-Match  header  string  true  "Project version got from ETag"
+// @Param        template  body  ProjectTemplate  true  "Whether project is template"
+// @Success      200  {object}  map[string]any
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      412  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/projects/{projectId}/template [put]
+func (h *Handler) SetProjectTemplate(context *gin.Context) {
+	project, err := model.GetProject(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	var request ProjectTemplate
+	if err = context.BindJSON(&request); err != nil {
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "неверный формат данных"})
+		return
+	}
+
+	project.IsTemplate = request.IsTemplate
+	version, err := h.repo.SetProjectTemplate(context.Request.Context(), project)
+	if err != nil {
+		context.AbortWithStatusJSON(repoErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+
+	context.Header("ETag", projectETag(version))
+	context.JSON(http.StatusOK, gin.H{"message": "successfully changed"})
+}
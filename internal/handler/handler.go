@@ -7,18 +7,27 @@ import (
 	"net/http"
 	"path/filepath"
 	"strings"
+	"time"
 
 	_ "tiflo/docs"
+	"tiflo/internal/gc"
 	"tiflo/internal/repository"
+	"tiflo/internal/trash"
+	"tiflo/model"
+	"tiflo/pkg/archive"
 	"tiflo/pkg/auth"
 	"tiflo/pkg/ffmpeg"
 	"tiflo/pkg/grpc/client"
 	pythonClient "tiflo/pkg/grpc/client"
 	pb "tiflo/pkg/grpc/generated"
 	"tiflo/pkg/hash"
+	"tiflo/pkg/lint"
 	"tiflo/pkg/redis"
+	"tiflo/pkg/storage"
+	"tiflo/pkg/waveform"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 	"github.com/swaggo/files"
@@ -34,8 +43,27 @@ type Handler struct {
 
 	hasher       hash.PasswordHasher
 	tokenManager auth.TokenManager
+	mediaSigner  auth.MediaSigner
 	pythonClient client.AI
 	mediaService ffmpeg.MediaService
+	storage      storage.Storage
+	archiver     archive.Archiver
+	trashConfig  trash.Config
+	uploadConfig UploadConfig
+	mediaLimits  model.MediaLimits
+
+	waveformZooms []int
+	captionConfig CaptionConfig
+
+	transcriptConfig TranscriptConfig
+
+	lockTTL time.Duration
+
+	mediaUrlTTL    time.Duration
+	voiceOutputDir string
+
+	linter     lint.Linter
+	lintConfig model.LintConfig
 }
 
 func initConfig(vp *viper.Viper, configPath string) error {
@@ -94,7 +122,31 @@ func NewHandler(logger *logrus.Logger) *Handler {
 		logger.Info("connected to image2text")
 		image2textClient := pb.NewImageCaptioningClient(conn)
 
-		pythonCl = pythonClient.NewPythonClient(logger, voice2textClient, image2textClient)
+		// OCR and speech recognition are optional, descriptions are made without on-screen text
+		// and transcript can't be made when they are not set
+		var ocrClient pb.TextRecognitionClient
+		if ocrAddress := vp.GetString("python.ocr.address"); ocrAddress != "" {
+			conn, err = grpc.Dial(ocrAddress, grpc.WithInsecure(), grpc.WithBlock())
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			logger.Info("connected to ocr")
+			ocrClient = pb.NewTextRecognitionClient(conn)
+		}
+
+		var speechClient pb.SpeechRecognitionClient
+		if speechAddress := vp.GetString("python.speech2text.address"); speechAddress != "" {
+			conn, err = grpc.Dial(speechAddress, grpc.WithInsecure(), grpc.WithBlock())
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			logger.Info("connected to speech2text")
+			speechClient = pb.NewSpeechRecognitionClient(conn)
+		}
+
+		pythonCl = pythonClient.NewPythonClient(logger, voice2textClient, image2textClient, ocrClient, speechClient)
 	}
 
 	tokenManager, err := auth.NewManager(vp.GetString("auth.secret"))
@@ -102,6 +154,16 @@ func NewHandler(logger *logrus.Logger) *Handler {
 		logger.Fatalln(err)
 	}
 
+	// media URLs are signed with own key if it is set, so it can be rotated apart from JWT key
+	mediaSecret := vp.GetString("auth.mediaSecret")
+	if mediaSecret == "" {
+		mediaSecret = vp.GetString("auth.secret")
+	}
+	mediaSigner, err := auth.NewMediaSigner(mediaSecret)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+
 	redisConfig := redis.InitRedisConfig(vp)
 
 	redisClient, err := redis.NewRedisClient(context.Background(), redisConfig, logger)
@@ -109,14 +171,92 @@ func NewHandler(logger *logrus.Logger) *Handler {
 		logger.Fatalln(err)
 	}
 
+	// organisation style guide, projects without own style guide are checked against it
+	lintConfig := lint.DefaultConfig()
+	if vp.IsSet("lint") {
+		if err = vp.UnmarshalKey("lint", &lintConfig); err != nil {
+			logger.Fatalln(err)
+		}
+	}
+
+	// lists from config replace default ones instead of overwriting their first elements
+	zeroFields := func(config *mapstructure.DecoderConfig) { config.ZeroFields = true }
+
+	mediaLimits := model.DefaultMediaLimits()
+	if vp.IsSet("media") {
+		if err = vp.UnmarshalKey("media", &mediaLimits, zeroFields); err != nil {
+			logger.Fatalln(err)
+		}
+	}
+
+	previewConfig := ffmpeg.DefaultPreviewConfig()
+	if vp.IsSet("preview") {
+		if err = vp.UnmarshalKey("preview", &previewConfig, zeroFields); err != nil {
+			logger.Fatalln(err)
+		}
+		if len(previewConfig.Ladder) == 0 {
+			logger.Fatalln("preview ladder is empty")
+		}
+		thumbnails := previewConfig.Thumbnails
+		if thumbnails.Interval <= 0 || thumbnails.Width <= 0 || thumbnails.Columns <= 0 || thumbnails.Rows <= 0 {
+			logger.Fatalln("preview thumbnails must have positive interval, width, columns and rows")
+		}
+	}
+
+	waveformZooms := waveform.DefaultZooms()
+	if vp.IsSet("waveform.zooms") {
+		waveformZooms = vp.GetIntSlice("waveform.zooms")
+	}
+	if len(waveformZooms) == 0 {
+		logger.Fatalln("waveform zooms are empty")
+	}
+	for _, zoom := range waveformZooms {
+		if zoom <= 0 {
+			logger.Fatalln("waveform zoom must be positive: ", zoom)
+		}
+	}
+
+	storageConfig := storage.InitConfig(vp, PathForMedia)
+	mediaStorage, err := storage.NewStorage(context.Background(), storageConfig, logger)
+	if err != nil {
+		logger.Fatalln("error during connecting to storage ", err)
+	}
+
+	voiceOutputDir := vp.GetString("python.outputDir")
+	if voiceOutputDir == "" {
+		voiceOutputDir = PathForMedia
+	}
+
+	trashConfig := trash.InitConfig(vp)
+	if repos != nil {
+		go trash.NewPurger(logger, repos, trashConfig, mediaStorage).Run(context.Background())
+		go gc.NewCollector(logger, repos, gc.InitConfig(vp), mediaStorage).Run(context.Background())
+	}
+
 	return &Handler{
 		logger:       logger.WithField("component", "handler"),
 		pythonClient: pythonCl,
 		repo:         repos,
 		hasher:       hash.NewSHA256Hasher(vp.GetString("auth.salt")),
 		tokenManager: tokenManager,
+		mediaSigner:  mediaSigner,
 		redisClient:  redisClient,
-		mediaService: ffmpeg.NewMediaService(PathForMedia, logger),
+		mediaService: ffmpeg.NewMediaService(mediaStorage, storageConfig.TempDir, previewConfig, logger),
+		storage:      mediaStorage,
+		archiver:     archive.NewArchiver(mediaStorage, logger),
+		trashConfig:  trashConfig,
+		uploadConfig: initUploadConfig(vp, storageConfig.TempDir),
+		mediaLimits:  mediaLimits,
+		lockTTL:      redisConfig.LockTTL,
+		linter:       lint.NewLinter(logger),
+		lintConfig:   lintConfig,
+
+		mediaUrlTTL:    storageConfig.PresignTTL,
+		voiceOutputDir: voiceOutputDir,
+		waveformZooms:  waveformZooms,
+		captionConfig:  initCaptionConfig(vp),
+
+		transcriptConfig: initTranscriptConfig(vp),
 	}
 }
 
@@ -124,10 +264,15 @@ func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", c.GetHeader("Origin"))
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, If-Match, X-Session-Id, Tus-Resumable, Upload-Length, Upload-Offset, Upload-Metadata")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "ETag, Location, Tus-Resumable, Tus-Version, Upload-Offset, Upload-Length, "+model.NotApprovedHeader)
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, HEAD, PUT, DELETE, PATCH")
 
 		if c.Request.Method == "OPTIONS" {
+			// tus clients discover protocol capabilities with OPTIONS
+			c.Writer.Header().Set("Tus-Resumable", model.TusVersion)
+			c.Writer.Header().Set("Tus-Version", model.TusVersion)
+			c.Writer.Header().Set("Tus-Extension", model.TusExtensions)
 			c.AbortWithStatus(204)
 			return
 		}
@@ -153,6 +298,9 @@ func (h *Handler) InitRouter() *gin.Engine {
 			authRouter.POST("/logout", h.Logout)
 		}
 
+		// signed URL authorizes download itself, so it can be given to players and other services until it expires
+		apiGroup.GET("/media/*name", h.ServeMedia)
+
 		routerWithAuthCheck := apiGroup.Group("/")
 		routerWithAuthCheck.Use(h.AuthCheck())
 
@@ -160,19 +308,66 @@ func (h *Handler) InitRouter() *gin.Engine {
 		{
 			projectsRouter.POST("/", h.CreateProject)
 			projectsRouter.GET("/", h.GetProjects)
-			projectsRouter.PATCH("/:projectId/", h.UpdateProjectName)
-			projectsRouter.DELETE("/:projectId/", h.DeleteProject)
-			projectsRouter.GET("/:projectId/", h.GetProjectInfo)
-
-			projectsRouter.POST("/:projectId/media", h.UploadMedia)
+			projectsRouter.POST("/import", h.ImportProject)
+			projectsRouter.GET("/trash", h.GetTrash)
+			projectsRouter.POST("/trash/:projectId/restore", h.RestoreProject)
+
+			projectRouter := projectsRouter.Group("/:projectId")
+			projectRouter.Use(h.ProjectAccessCheck())
+			{
+				projectRouter.PATCH("/", h.IfMatchCheck(), h.UpdateProjectName)
+				projectRouter.DELETE("/", h.IfMatchCheck(), h.DeleteProject)
+				projectRouter.GET("/", h.GetProjectInfo)
+				projectRouter.POST("/duplicate", h.IfMatchCheck(), h.DuplicateProject)
+				projectRouter.GET("/export", h.ExportProject)
+				projectRouter.PUT("/template", h.IfMatchCheck(), h.SetProjectTemplate)
+
+				projectRouter.POST("/media", h.IfMatchCheck(), h.ProjectEditLock(), h.UploadMedia)
+				projectRouter.POST("/uploads", h.TusCheck(), h.IfMatchCheck(), h.CreateUpload)
+				projectRouter.HEAD("/uploads/:uploadId", h.TusCheck(), h.GetUploadOffset)
+				projectRouter.PATCH("/uploads/:uploadId", h.TusCheck(), h.UploadChunk)
+				projectRouter.DELETE("/uploads/:uploadId", h.TusCheck(), h.DeleteUpload)
+
+				projectRouter.POST("/voice", h.VoiceText)
+				projectRouter.DELETE("/audio-part/:audioPartId", h.IfMatchCheck(), h.ProjectEditLock(), h.DeleteAudioPart)
+				projectRouter.PUT("/audio-part/:audioPartId", h.IfMatchCheck(), h.ProjectEditLock(), h.ChangeCommentText)
+				projectRouter.PATCH("/audio-part/:audioPartId/status", h.IfMatchCheck(), h.ProjectEditLock(), h.ChangeAudioPartStatus)
+				projectRouter.POST("/replace/preview", h.PreviewReplace)
+				projectRouter.POST("/replace", h.IfMatchCheck(), h.ProjectEditLock(), h.ReplaceText)
+				projectRouter.GET("/readiness", h.GetDeliveryReadiness)
+				projectRouter.GET("/frame", h.GetFrame)
+				projectRouter.GET("/waveform", h.GetWaveform)
+				projectRouter.POST("/transcript", h.IfMatchCheck(), h.ProjectEditLock(), h.CreateTranscript)
+				projectRouter.GET("/transcript", h.GetTranscript)
+				projectRouter.GET("/transcript/export", h.ExportTranscript)
+
+				projectRouter.GET("/lint", h.LintProject)
+				projectRouter.GET("/lint/config", h.GetLintConfig)
+				projectRouter.PUT("/lint/config", h.IfMatchCheck(), h.SetLintConfig)
+
+				projectRouter.GET("/lexicon", h.GetProjectLexicon)
+				projectRouter.POST("/lexicon", h.IfMatchCheck(), h.CreateProjectLexiconEntry)
+				projectRouter.DELETE("/lexicon/:entryId", h.IfMatchCheck(), h.DeleteProjectLexiconEntry)
+				projectRouter.PUT("/voice/settings", h.IfMatchCheck(), h.SetVoiceSettings)
+				projectRouter.POST("/revoice", h.IfMatchCheck(), h.ProjectEditLock(), h.RevoiceProject)
+
+				projectRouter.GET("/notes", h.GetNotes)
+				projectRouter.POST("/notes", h.IfMatchCheck(), h.CreateNote)
+				projectRouter.PATCH("/notes/:noteId/resolve", h.IfMatchCheck(), h.ResolveNote)
+				projectRouter.POST("/video/comment", h.IfMatchCheck(), h.ProjectEditLock(), h.CreateComment)
+				projectRouter.POST("/image/comment", h.IfMatchCheck(), h.ProjectEditLock(), h.ImageToText)
+
+				projectRouter.POST("/audio", h.IfMatchCheck(), h.ConcatAudio)
+			}
+		}
 
-			projectsRouter.POST("/:projectId/voice", h.VoiceText)
-			projectsRouter.DELETE("/:projectId/audio-part/:audioPartId", h.DeleteAudioPart)
-			projectsRouter.PUT("/:projectId/audio-part/:audioPartId", h.ChangeCommentText)
-			projectsRouter.POST("/:projectId/video/comment", h.CreateComment)
-			projectsRouter.POST("/:projectId/image/comment", h.ImageToText)
+		routerWithAuthCheck.GET("/search", h.Search)
 
-			projectsRouter.POST("/:projectId/audio", h.ConcatAudio)
+		lexiconRouter := routerWithAuthCheck.Group("/lexicon")
+		{
+			lexiconRouter.GET("", h.GetUserLexicon)
+			lexiconRouter.POST("", h.CreateUserLexiconEntry)
+			lexiconRouter.DELETE("/:entryId", h.DeleteUserLexiconEntry)
 		}
 
 	}
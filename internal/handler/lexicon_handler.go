@@ -0,0 +1,415 @@
+package handler
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"sort"
+	"strings"
+	"tiflo/model"
+	"tiflo/pkg/lexicon"
+)
+
+// prepareVoiceInput applies user and project lexicon to text, the result is what is sent to TTS
+func (h *Handler) prepareVoiceInput(ctx context.Context, project model.Project, text string) (string, error) {
+	entries, err := h.repo.GetLexicon(ctx, project.UserId, &project.ProjectId)
+	if err != nil {
+		return "", err
+	}
+
+	return lexicon.Prepare(lexicon.Merge(entries), text, project.SSML), nil
+}
+
+// voiceText voices text of project description, returns name of wav file and text which was sent to TTS
+func (h *Handler) voiceText(ctx context.Context, project model.Project, text string) (string, string, error) {
+	voiceInput, err := h.prepareVoiceInput(ctx, project, text)
+	if err != nil {
+		return "", "", err
+	}
+
+	path, err := h.voice(ctx, voiceInput, project.VoiceSettings())
+	if err != nil {
+		return "", "", err
+	}
+
+	return path, voiceInput, nil
+}
+
+func bindLexiconEntry(context *gin.Context) (model.LexiconEntryCreate, bool) {
+	var entry model.LexiconEntryCreate
+	if err := context.BindJSON(&entry); err != nil {
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "неверный формат данных"})
+		return entry, false
+	}
+
+	entry.Term = strings.TrimSpace(entry.Term)
+	if entry.Term == "" || (entry.Alias == "" && entry.Phoneme == "") {
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "нужно указать термин и произношение или замену"})
+		return entry, false
+	}
+
+	if entry.Phoneme != "" {
+		if entry.Alphabet == "" {
+			entry.Alphabet = model.AlphabetIPA
+		}
+		if entry.Alphabet != model.AlphabetIPA && entry.Alphabet != model.AlphabetXSampa {
+			context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "неизвестный фонетический алфавит"})
+			return entry, false
+		}
+	}
+
+	return entry, true
+}
+
+// GetUserLexicon godoc
+// @Summary      Get user lexicon
+// @Description  Get pronunciation entries which are used in all user's projects
+// @Tags         Lexicon
+// @Produce      json
+// @Success      200  {object}  []model.LexiconEntry
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/lexicon [get]
+func (h *Handler) GetUserLexicon(context *gin.Context) {
+	userId, err := model.GetUserId(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+		return
+	}
+
+	entries, err := h.repo.GetLexicon(context.Request.Context(), userId, nil)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, entries)
+}
+
+// CreateUserLexiconEntry godoc
+// @Summary      Add user lexicon entry
+// @Description  Add pronunciation entry which is used in all user's projects
+// @Tags         Lexicon
+// @Accept       json
+// @Produce      json
+// @Param        entry  body  model.LexiconEntryCreate  true  "Term and its pronunciation"
+// @Success      200  {object}  model.LexiconEntry
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      409  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/lexicon [post]
+func (h *Handler) CreateUserLexiconEntry(context *gin.Context) {
+	userId, err := model.GetUserId(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+		return
+	}
+
+	h.createLexiconEntry(context, userId)
+}
+
+// DeleteUserLexiconEntry godoc
+// @Summary      Delete user lexicon entry
+// @Description  Delete pronunciation entry of user lexicon
+// @Tags         Lexicon
+// @Produce      json
+// @Param        entryId  path  string  true  "Entry Id"
+// @Success      200  {object}  map[string]any
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      404  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/lexicon/{entryId} [delete]
+func (h *Handler) DeleteUserLexiconEntry(context *gin.Context) {
+	userId, err := model.GetUserId(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+		return
+	}
+
+	h.deleteLexiconEntry(context, userId)
+}
+
+// GetProjectLexicon godoc
+// @Summary      Get project lexicon
+// @Description  Get pronunciation entries used in project: user entries and project entries, which override them
+// @Tags         Lexicon
+// @Produce      json
+// @Param        projectId  path  string  true  "Project Id"
+// @Success      200  {object}  []model.LexiconEntry
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/projects/{projectId}/lexicon [get]
+func (h *Handler) GetProjectLexicon(context *gin.Context) {
+	project, err := model.GetProject(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	entries, err := h.repo.GetLexicon(context.Request.Context(), project.UserId, &project.ProjectId)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, lexicon.Merge(entries))
+}
+
+// CreateProjectLexiconEntry godoc
+// @Summary      Add project lexicon entry
+// @Description  Add pronunciation entry used only in this project
+// @Tags         Lexicon
+// @Accept       json
+// @Produce      json
+// @Param        projectId  path  string  true  "Project Id"
+// @Param        If-Match  header  string  true  "Project version got from ETag"
+// @Param        entry  body  model.LexiconEntryCreate  true  "Term and its pronunciation"
+// @Success      200  {object}  model.LexiconEntry
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      409  {object}  error
+// @Failure      412  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/projects/{projectId}/lexicon [post]
+func (h *Handler) CreateProjectLexiconEntry(context *gin.Context) {
+	project, err := model.GetProject(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	entry, ok := bindLexiconEntry(context)
+	if !ok {
+		return
+	}
+
+	created, version, err := h.repo.CreateProjectLexiconEntry(context.Request.Context(), project, model.LexiconEntry{
+		Term:     entry.Term,
+		Alias:    entry.Alias,
+		Phoneme:  entry.Phoneme,
+		Alphabet: entry.Alphabet,
+	})
+	if err != nil {
+		context.AbortWithStatusJSON(repoErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+
+	context.Header("ETag", projectETag(version))
+	context.JSON(http.StatusOK, created)
+}
+
+// DeleteProjectLexiconEntry godoc
+// @Summary      Delete project lexicon entry
+// @Description  Delete pronunciation entry of project lexicon
+// @Tags         Lexicon
+// @Produce      json
+// @Param        projectId  path  string  true  "Project Id"
+// @Param        entryId  path  string  true  "Entry Id"
+// @Param        If-Match  header  string  true  "Project version got from ETag"
+// @Success      200  {object}  map[string]any
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      404  {object}  error
+// @Failure      412  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/projects/{projectId}/lexicon/{entryId} [delete]
+func (h *Handler) DeleteProjectLexiconEntry(context *gin.Context) {
+	project, err := model.GetProject(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	entryId, err := uuid.Parse(context.Param("entryId"))
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	version, err := h.repo.DeleteProjectLexiconEntry(context.Request.Context(), project, entryId)
+	if err != nil {
+		context.AbortWithStatusJSON(repoErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+
+	context.Header("ETag", projectETag(version))
+	context.JSON(http.StatusOK, gin.H{"message": "successfully deleted"})
+}
+
+// createLexiconEntry adds entry to user lexicon, project entries are added with project version check
+func (h *Handler) createLexiconEntry(context *gin.Context, userId uuid.UUID) {
+	entry, ok := bindLexiconEntry(context)
+	if !ok {
+		return
+	}
+
+	created, err := h.repo.CreateLexiconEntry(context.Request.Context(), model.LexiconEntry{
+		UserId:   userId,
+		Term:     entry.Term,
+		Alias:    entry.Alias,
+		Phoneme:  entry.Phoneme,
+		Alphabet: entry.Alphabet,
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == model.Conflict {
+			status = http.StatusConflict
+		}
+		context.AbortWithStatusJSON(status, gin.H{"message": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, created)
+}
+
+func (h *Handler) deleteLexiconEntry(context *gin.Context, userId uuid.UUID) {
+	entryId, err := uuid.Parse(context.Param("entryId"))
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	err = h.repo.DeleteLexiconEntry(context.Request.Context(), model.LexiconEntry{
+		EntryId: entryId,
+		UserId:  userId,
+	})
+	if err != nil {
+		context.AbortWithStatusJSON(repoErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "successfully deleted"})
+}
+
+// SetVoiceSettings godoc
+// @Summary      Set project voice settings
+// @Description  Set TTS voice and switch between plain text and SSML input. Already voiced parts are not changed, use revoice for it
+// @Tags         Lexicon
+// @Accept       json
+// @Produce      json
+// @Param        projectId  path  string  true  "Project Id"
+// @Param        If-Match  header  string  true  "Project version got from ETag"
+// @Param        settings  body  model.VoiceSettings  true  "Voice settings"
+// @Success      200  {object}  map[string]any
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      412  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/projects/{projectId}/voice/settings [put]
+func (h *Handler) SetVoiceSettings(context *gin.Context) {
+	project, err := model.GetProject(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	var settings model.VoiceSettings
+	if err = context.BindJSON(&settings); err != nil {
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "неверный формат данных"})
+		return
+	}
+
+	project.SSML = settings.SSML
+	project.Voice = settings.Voice
+	version, err := h.repo.SetVoiceSettings(context.Request.Context(), project)
+	if err != nil {
+		context.AbortWithStatusJSON(repoErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+
+	context.Header("ETag", projectETag(version))
+	context.JSON(http.StatusOK, gin.H{"message": "настройки озвучки сохранены"})
+}
+
+// RevoiceProject godoc
+// @Summary      Re-voice project descriptions
+// @Description  Re-voice descriptions whose TTS input or voice changed after lexicon or voice settings were edited, later parts are shifted by change of duration
+// @Tags         Lexicon
+// @Produce      json
+// @Param        projectId  path  string  true  "Project Id"
+// @Param        If-Match  header  string  true  "Project version got from ETag"
+// @Success      200  {object}  map[string]any
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      412  {object}  error
+// @Failure      423  {object}  map[string]any
+// @Failure      500  {object}  error
+// @Router       /api/projects/{projectId}/revoice [post]
+func (h *Handler) RevoiceProject(context *gin.Context) {
+	project, err := model.GetProject(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	entries, err := h.repo.GetLexicon(context.Request.Context(), project.UserId, &project.ProjectId)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	entries = lexicon.Merge(entries)
+
+	sort.SliceStable(project.AudioParts, func(i, j int) bool {
+		return project.AudioParts[i].Start < project.AudioParts[j].Start
+	})
+
+	// shift is accumulated change of duration of all re-voiced parts before current one
+	var shift int64
+	revoiced := make([]uuid.UUID, 0)
+	updated := make([]model.AudioPart, 0, len(project.AudioParts))
+	for _, part := range project.AudioParts {
+		part.Start += shift
+
+		if part.Text != "" {
+			voiceInput := lexicon.Prepare(entries, part.Text, project.SSML)
+			if voiceInput != part.VoiceInput || project.Voice != part.Voice {
+				path, err := h.voice(context.Request.Context(), voiceInput, project.VoiceSettings())
+				if err != nil {
+					h.logger.Error(err)
+					context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+					return
+				}
+
+				_, durationInt, err := h.mediaService.GetAudioDurationWav(context.Request.Context(), path)
+				if err != nil {
+					h.logger.Error(err)
+					context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+					return
+				}
+
+				shift += durationInt - part.Duration
+				part.Duration = durationInt
+				part.Path = path
+				part.VoiceInput = voiceInput
+				part.Voice = project.Voice
+				revoiced = append(revoiced, part.PartId)
+			}
+		}
+
+		updated = append(updated, part)
+	}
+
+	if len(revoiced) == 0 {
+		context.Header("ETag", projectETag(project.Version))
+		context.JSON(http.StatusOK, gin.H{"message": "нет описаний для переозвучки", "revoiced": revoiced})
+		return
+	}
+
+	version, err := h.repo.UpdateTimeline(context.Request.Context(), project.UserId, model.TimelineUpdate{
+		ProjectId:    project.ProjectId,
+		Version:      project.Version,
+		FencingToken: model.GetLockToken(context),
+		Updated:      updated,
+	})
+	if err != nil {
+		h.logger.Error(err)
+		context.AbortWithStatusJSON(repoErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+
+	context.Header("ETag", projectETag(version))
+	context.JSON(http.StatusOK, gin.H{"message": "successfully revoiced", "revoiced": revoiced})
+}
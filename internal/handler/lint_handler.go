@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"tiflo/model"
+)
+
+// projectLintConfig returns style guide of project or organisation style guide if project has no own one
+func (h *Handler) projectLintConfig(project model.Project) model.LintConfig {
+	if project.LintConfig != nil {
+		return *project.LintConfig
+	}
+
+	return h.lintConfig
+}
+
+// LintProject godoc
+// @Summary      Check project descriptions
+// @Description  Check all descriptions of project against style guide
+// @Tags         Lint
+// @Produce      json
+// @Param        projectId  path  string  true  "Project Id"
+// @Success      200  {object}  []model.LintWarning
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/projects/{projectId}/lint [get]
+func (h *Handler) LintProject(context *gin.Context) {
+	project, err := model.GetProject(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, h.linter.LintProject(h.projectLintConfig(project), project))
+}
+
+// GetLintConfig godoc
+// @Summary      Get project style guide
+// @Description  Get style guide which is used to check project descriptions
+// @Tags         Lint
+// @Produce      json
+// @Param        projectId  path  string  true  "Project Id"
+// @Success      200  {object}  model.LintConfig
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/projects/{projectId}/lint/config [get]
+func (h *Handler) GetLintConfig(context *gin.Context) {
+	project, err := model.GetProject(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, h.projectLintConfig(project))
+}
+
+// SetLintConfig godoc
+// @Summary      Set project style guide
+// @Description  Set own style guide of project, empty body resets project to organisation style guide
+// @Tags         Lint
+// @Accept       json
+// @Produce      json
+// @Param        projectId  path  string  true  "Project Id"
+// @Param        If-Match  header  string  true  "Project version got from ETag"
+// @Param        config  body  model.LintConfig  false  "Style guide"
+// @Success      200  {object}  map[string]any
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      412  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/projects/{projectId}/lint/config [put]
+func (h *Handler) SetLintConfig(context *gin.Context) {
+	project, err := model.GetProject(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	project.LintConfig = nil
+	if context.Request.ContentLength != 0 {
+		var config model.LintConfig
+		if err = context.BindJSON(&config); err != nil {
+			context.AbortWithStatusJSON(http.StatusBadRequest, "неверный формат данных")
+			return
+		}
+
+		if config.MaxWordsPerSecond < 0 || config.MaxLength < 0 || config.RepeatedWords < 0 {
+			context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "ограничения не могут быть отрицательными"})
+			return
+		}
+		project.LintConfig = &config
+	}
+
+	version, err := h.repo.SetLintConfig(context.Request.Context(), project)
+	if err != nil {
+		context.AbortWithStatusJSON(repoErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+
+	context.Header("ETag", projectETag(version))
+	context.JSON(http.StatusOK, gin.H{"message": "правила проверки сохранены"})
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"tiflo/model"
+	"tiflo/pkg/redis"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	projectLockPrefix = "project."
+	// maxSessionIdLength limits id of client session which is stored as lock holder
+	maxSessionIdLength = 64
+)
+
+// lockHolder returns id of client session which makes request, every request without session id is holder on its own
+func lockHolder(gCtx *gin.Context) string {
+	sessionId := gCtx.GetHeader(model.SessionIdHeader)
+	if sessionId == "" || len(sessionId) > maxSessionIdLength {
+		return "request:" + uuid.NewString()
+	}
+
+	return "session:" + sessionId
+}
+
+// ProjectEditLock serialises timeline changes of project across backend replicas.
+// Lease is prolonged while request is processed and its fencing token is passed to repository with changes.
+// Holder is session of client, so busy response tells whether project is edited from the same tab or another one.
+func (h *Handler) ProjectEditLock() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		release, ok := h.acquireEditLock(gCtx)
+		if !ok {
+			return
+		}
+		defer release()
+
+		gCtx.Next()
+	}
+}
+
+// acquireEditLock takes edit lock of project from context and puts its fencing token to context,
+// request is aborted if lock is busy. Returned function releases the lock.
+func (h *Handler) acquireEditLock(gCtx *gin.Context) (func(), bool) {
+	project, err := model.GetProject(gCtx)
+	if err != nil {
+		gCtx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return nil, false
+	}
+
+	holder := lockHolder(gCtx)
+	lock, err := h.redisClient.AcquireLock(gCtx.Request.Context(), projectLockPrefix+project.ProjectId.String(),
+		holder, h.lockTTL)
+	if errors.Is(err, redis.ErrLockBusy) {
+		retryAfter := int(math.Ceil(lock.TTL.Seconds()))
+		gCtx.Header("Retry-After", strconv.Itoa(retryAfter))
+		gCtx.AbortWithStatusJSON(http.StatusLocked, gin.H{
+			"message":     "проект редактируется, попробуйте позже",
+			"holder":      lock.Holder,
+			"sameSession": lock.Holder == holder,
+			"retryAfter":  retryAfter,
+		})
+		return nil, false
+	}
+	if err != nil {
+		gCtx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return nil, false
+	}
+
+	done := make(chan struct{})
+	go h.keepLock(lock, done)
+
+	gCtx.Set(model.LockTokenCtx, lock.Token)
+	return func() {
+		close(done)
+		if err := h.redisClient.ReleaseLock(context.Background(), lock); err != nil {
+			h.logger.Error("release project lock: ", err)
+		}
+	}, true
+}
+
+// keepLock extends lease until done is closed or lease is lost
+func (h *Handler) keepLock(lock redis.Lock, done <-chan struct{}) {
+	ticker := time.NewTicker(h.lockTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := h.redisClient.ExtendLock(context.Background(), lock, h.lockTTL); err != nil {
+				h.logger.Error("extend project lock: ", err)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"tiflo/model"
+	"tiflo/pkg/auth"
+	"tiflo/pkg/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	// system MIME table may map .ts to TypeScript sources, but here they are segments of HLS ladders
+	if err := mime.AddExtensionType(".ts", "video/mp2t"); err != nil {
+		panic(err)
+	}
+}
+
+// serveMedia streams object of storage to client, Range requests are supported when object is seekable
+func (h *Handler) serveMedia(context *gin.Context, name string) {
+	ctx := context.Request.Context()
+
+	info, err := h.storage.Stat(ctx, name)
+	if errors.Is(err, storage.ErrNotFound) {
+		context.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "файл не найден"})
+		return
+	}
+	if err != nil {
+		h.logger.Error(err)
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	reader, err := h.storage.Get(ctx, name)
+	if err != nil {
+		h.logger.Error(err)
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	defer reader.Close()
+
+	if seeker, ok := reader.(io.ReadSeeker); ok {
+		// ServeContent answers Range and conditional requests, so video can be seeked
+		http.ServeContent(context.Writer, context.Request, name, info.Modified, seeker)
+		return
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	context.DataFromReader(http.StatusOK, info.Size, contentType, reader, nil)
+}
+
+// ServeMedia godoc
+// @Summary      Download media file
+// @Description  Download media file by signed URL got from project info, Range requests are supported.
+// @Description  URLs in HLS playlists and WebVTT thumbnail indexes are signed the same way.
+// @Description  Media of S3 storage is redirected to presigned URL of storage.
+// @Tags         Media
+// @Produce      octet-stream
+// @Param        name  path  string  true  "Media file name"
+// @Param        expires  query  int  true  "Expiration time of URL, unix seconds"
+// @Param        signature  query  string  true  "Signature of URL"
+// @Success      200  {file}  file
+// @Success      206  {file}  file
+// @Success      307
+// @Failure      403  {object}  error
+// @Failure      404  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/media/{name} [get]
+func (h *Handler) ServeMedia(context *gin.Context) {
+	name := strings.TrimPrefix(context.Param("name"), "/")
+
+	expires, err := strconv.ParseInt(context.Query("expires"), 10, 64)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": auth.InvalidMediaSignature.Error()})
+		return
+	}
+
+	if err = h.mediaSigner.Verify(name, expires, context.Query("signature")); err != nil {
+		context.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": err.Error()})
+		return
+	}
+
+	// URL must not be cached longer than it is valid
+	context.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", expires-time.Now().Unix()))
+	switch path.Ext(name) {
+	case ".m3u8":
+		h.serveIndex(context, name, "application/vnd.apple.mpegurl", func(line string) string {
+			if line == "" || strings.HasPrefix(line, "#") {
+				return line
+			}
+			return h.mediaUrl(path.Join(path.Dir(name), line))
+		})
+	case ".vtt":
+		// thumbnail cue refers to region of sprite sheet, e.g. sheet_001.jpg#xywh=0,0,160,90
+		h.serveIndex(context, name, "text/vtt; charset=utf-8", func(line string) string {
+			sheet, region, found := strings.Cut(line, "#xywh=")
+			if !found || strings.Contains(sheet, " ") {
+				return line
+			}
+			return h.mediaUrl(path.Join(path.Dir(name), sheet)) + "#xywh=" + region
+		})
+	default:
+		h.redirectMedia(context, name, time.Unix(expires, 0))
+	}
+}
+
+// redirectMedia sends client to presigned URL of storage, so media bytes don't pass through backend,
+// media of storage without presigned URLs is streamed by backend
+func (h *Handler) redirectMedia(context *gin.Context, name string, expires time.Time) {
+	ttl := time.Until(expires)
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+
+	presigned, err := h.storage.Presign(context.Request.Context(), name, ttl)
+	if errors.Is(err, storage.ErrPresignNotSupported) {
+		h.serveMedia(context, name)
+		return
+	}
+	if err != nil {
+		h.logger.Error(err)
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	context.Redirect(http.StatusTemporaryRedirect, presigned)
+}
+
+// serveIndex serves HLS playlist or WebVTT index with signed URLs of files it refers to,
+// because player resolves their relative paths to URLs without signature
+func (h *Handler) serveIndex(context *gin.Context, name string, contentType string, rewrite func(line string) string) {
+	reader, err := h.storage.Get(context.Request.Context(), name)
+	if errors.Is(err, storage.ErrNotFound) {
+		context.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "файл не найден"})
+		return
+	}
+	if err != nil {
+		h.logger.Error(err)
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	defer reader.Close()
+
+	var index bytes.Buffer
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		index.WriteString(rewrite(scanner.Text()))
+		index.WriteByte('\n')
+	}
+	if err = scanner.Err(); err != nil {
+		h.logger.Error(err)
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	context.Data(http.StatusOK, contentType, index.Bytes())
+}
+
+// saveUploadedFile puts file of multipart form to storage under given name
+func (h *Handler) saveUploadedFile(ctx context.Context, file *multipart.FileHeader, name string) error {
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	return h.storage.Put(ctx, name, src, file.Size)
+}
+
+// mediaUrl returns signed URL which client downloads file with until mediaUrlTTL passes, empty name gives empty URL
+func (h *Handler) mediaUrl(name string) string {
+	if name == "" {
+		return ""
+	}
+
+	expires := time.Now().Add(h.mediaUrlTTL)
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+	query.Set("signature", h.mediaSigner.Sign(name, expires))
+
+	return "/api/media/" + (&url.URL{Path: name}).EscapedPath() + "?" + query.Encode()
+}
+
+// fillMediaUrls sets download URLs of project files
+func (h *Handler) fillMediaUrls(project *model.Project) {
+	project.VideoUrl = h.mediaUrl(project.VideoPath)
+	project.PreviewUrl = h.mediaUrl(project.ImagePath)
+	project.ProxyUrl = h.mediaUrl(project.ProxyPath)
+	project.HlsUrl = h.mediaUrl(project.HlsPath)
+	project.OutputHlsUrl = h.mediaUrl(project.OutputHlsPath)
+	project.ThumbnailsUrl = h.mediaUrl(project.ThumbnailsPath)
+	project.OutputUrl = h.mediaUrl(project.OutputPath)
+
+	for i := range project.AudioParts {
+		project.AudioParts[i].Url = h.mediaUrl(project.AudioParts[i].Path)
+	}
+}
+
+// adoptVoiced moves file written by voice2text service to storage,
+// nothing has to be done when local storage keeps files in the same directory
+func (h *Handler) adoptVoiced(ctx context.Context, name string) error {
+	if local, ok := h.storage.(*storage.LocalStorage); ok && filepath.Clean(local.Dir()) == filepath.Clean(h.voiceOutputDir) {
+		return nil
+	}
+
+	path := filepath.Join(h.voiceOutputDir, name)
+	if err := storage.PutFile(ctx, h.storage, name, path); err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil {
+		h.logger.Error(err)
+	}
+
+	return nil
+}
+
+// exposeFiles makes files of storage readable by python service from inputDir and returns their paths there,
+// files are copied only when local storage keeps them in other directory. Returned func removes copies.
+// Only plain file names are accepted, so path outside of inputDir is never given to the service.
+func (h *Handler) exposeFiles(ctx context.Context, inputDir string, names []string) ([]string, func(), error) {
+	for _, name := range names {
+		if name == "" || name == "." || name == ".." || filepath.Base(name) != name {
+			return nil, nil, fmt.Errorf("wrong media name %q", name)
+		}
+	}
+
+	paths := make([]string, 0, len(names))
+	if local, ok := h.storage.(*storage.LocalStorage); ok && filepath.Clean(local.Dir()) == filepath.Clean(inputDir) {
+		for _, name := range names {
+			paths = append(paths, filepath.Join(inputDir, name))
+		}
+		return paths, func() {}, nil
+	}
+
+	cleanup := func() {
+		for _, path := range paths {
+			if err := os.Remove(path); err != nil {
+				h.logger.Error(err)
+			}
+		}
+	}
+
+	for _, name := range names {
+		path := filepath.Join(inputDir, name)
+		if err := storage.GetFile(ctx, h.storage, name, path); err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, cleanup, nil
+}
+
+// voice voices prepared text and returns name of wav file in storage
+func (h *Handler) voice(ctx context.Context, voiceInput string, settings model.VoiceSettings) (string, error) {
+	path, err := h.pythonClient.VoiceTheText(ctx, voiceInput, settings)
+	if err != nil {
+		return "", err
+	}
+
+	if err = h.adoptVoiced(ctx, path); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
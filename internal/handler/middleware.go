@@ -4,7 +4,9 @@ import (
 	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
 	"net/http"
+	"strconv"
 	"strings"
 	"tiflo/model"
 )
@@ -48,3 +50,86 @@ func (h *Handler) AuthCheck() gin.HandlerFunc {
 		gCtx.Next()
 	}
 }
+
+// ProjectAccessCheck resolves :projectId against the current user once and puts loaded project into context,
+// so handlers of project sub-resources never work with projects of other users
+func (h *Handler) ProjectAccessCheck() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		projectId, err := uuid.Parse(gCtx.Param("projectId"))
+		if err != nil {
+			gCtx.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+			return
+		}
+
+		userId, err := model.GetUserId(gCtx)
+		if err != nil {
+			gCtx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+			return
+		}
+
+		project, err := h.repo.GetProject(gCtx.Request.Context(), model.Project{ProjectId: projectId, UserId: userId})
+		if err != nil {
+			if errors.Is(err, model.NotFound) {
+				gCtx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "проект не найден"})
+				return
+			}
+
+			gCtx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+			return
+		}
+
+		gCtx.Set(model.ProjectCtx, project)
+		gCtx.Next()
+	}
+}
+
+// projectETag formats project version as strong entity tag
+func projectETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// IfMatchCheck requires If-Match header on mutating project endpoints and rejects requests made with
+// stale project version. Version is checked again by repository in the same transaction as changes.
+func (h *Handler) IfMatchCheck() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		ifMatch := gCtx.GetHeader("If-Match")
+		if ifMatch == "" {
+			gCtx.AbortWithStatusJSON(http.StatusPreconditionRequired, gin.H{"message": "не указан заголовок If-Match"})
+			return
+		}
+
+		project, err := model.GetProject(gCtx)
+		if err != nil {
+			gCtx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+			return
+		}
+
+		currentETag := projectETag(project.Version)
+		for _, tag := range strings.Split(ifMatch, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == currentETag {
+				gCtx.Next()
+				return
+			}
+		}
+
+		gCtx.Header("ETag", currentETag)
+		gCtx.AbortWithStatusJSON(http.StatusPreconditionFailed, gin.H{"message": "проект был изменён, обновите данные"})
+	}
+}
+
+// repoErrorStatus maps errors of project changes to http statuses
+func repoErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, model.VersionMismatch):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, model.NotFound):
+		return http.StatusNotFound
+	case errors.Is(err, model.LockLost), errors.Is(err, model.Conflict):
+		return http.StatusConflict
+	case errors.Is(err, model.InvalidMedia):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"time"
+
+	"tiflo/model"
+)
+
+// projectMembers returns users who have access to project, for now it is only project owner
+func projectMembers(project model.Project) map[uuid.UUID]bool {
+	return map[uuid.UUID]bool{project.UserId: true}
+}
+
+// noteOnTimeline returns note with time on described timeline of project, time of note anchored
+// to video timestamp is kept in time of original video
+func noteOnTimeline(project model.Project, note model.Note) model.Note {
+	if note.PartId == nil {
+		note.Time = model.TimelineTime(project.AudioParts, note.Time)
+	}
+
+	return note
+}
+
+// CreateNote godoc
+// @Summary      Create reviewer note
+// @Description  Create note anchored to audio part or to timestamp (hh:mm:ss.ms) of described timeline, or reply
+// @Description  to existing note. Note anchored to timestamp stays at the same moment of video when descriptions change.
+// @Tags         Note
+// @Accept       json
+// @Produce      json
+// @Param        projectId  path  string  true  "Project Id"
+// @Param        If-Match  header  string  true  "Project version got from ETag"
+// @Param        note  body  model.NoteCreate  true  "Note"
+// @Success      200  {object}  model.Note
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      404  {object}  error
+// @Failure      412  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/projects/{projectId}/notes [post]
+func (h *Handler) CreateNote(context *gin.Context) {
+	project, err := model.GetProject(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	userId, err := model.GetUserId(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+		return
+	}
+
+	var noteCreate model.NoteCreate
+	if err = context.BindJSON(&noteCreate); err != nil {
+		context.AbortWithStatusJSON(http.StatusBadRequest, "неверный формат данных")
+		return
+	}
+
+	members := projectMembers(project)
+	for _, mention := range noteCreate.Mentions {
+		if !members[mention] {
+			context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "упомянутый пользователь не участвует в проекте"})
+			return
+		}
+	}
+
+	note := model.Note{
+		ProjectId: project.ProjectId,
+		UserId:    userId,
+		Text:      noteCreate.Text,
+		Mentions:  noteCreate.Mentions,
+	}
+
+	switch {
+	case noteCreate.ParentId != nil:
+		// reply is placed in thread of root note and shares its anchor
+		parent, err := h.repo.GetNote(context.Request.Context(), project.ProjectId, *noteCreate.ParentId)
+		if err != nil {
+			context.AbortWithStatusJSON(repoErrorStatus(err), gin.H{"message": err.Error()})
+			return
+		}
+
+		note.ParentId = &parent.NoteId
+		if parent.ParentId != nil {
+			note.ParentId = parent.ParentId
+		}
+		note.PartId = parent.PartId
+		note.Time = parent.Time
+	case noteCreate.PartId != nil:
+		var found bool
+		for _, part := range project.AudioParts {
+			if part.PartId == *noteCreate.PartId {
+				note.PartId = &part.PartId
+				note.Time = part.Start
+				found = true
+				break
+			}
+		}
+		if !found {
+			context.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": model.NotFound.Error()})
+			return
+		}
+
+		if noteCreate.Time != "" {
+			note.Time = h.mediaService.ConvertTimeFromString(noteCreate.Time)
+		}
+	case noteCreate.Time != "":
+		note.Time = model.VideoTime(project.AudioParts, h.mediaService.ConvertTimeFromString(noteCreate.Time))
+	default:
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "нужно указать фрагмент, время или родительскую заметку"})
+		return
+	}
+
+	note, version, err := h.repo.CreateNote(context.Request.Context(), project, note)
+	if err != nil {
+		context.AbortWithStatusJSON(repoErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+
+	context.Header("ETag", projectETag(version))
+	context.JSON(http.StatusOK, noteOnTimeline(project, note))
+}
+
+// GetNotes godoc
+// @Summary      Get project notes
+// @Description  Get note threads of project, replies are nested into root notes
+// @Tags         Note
+// @Produce      json
+// @Param        projectId  path  string  true  "Project Id"
+// @Success      200  {object}  []model.Note
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/projects/{projectId}/notes [get]
+func (h *Handler) GetNotes(context *gin.Context) {
+	project, err := model.GetProject(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	notes, err := h.repo.GetNotes(context.Request.Context(), project.ProjectId)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	for i := range notes {
+		notes[i] = noteOnTimeline(project, notes[i])
+	}
+
+	context.JSON(http.StatusOK, model.BuildNoteThreads(notes))
+}
+
+// ResolveNote godoc
+// @Summary      Resolve or reopen note thread
+// @Description  Mark note thread as resolved or unresolved
+// @Tags         Note
+// @Accept       json
+// @Produce      json
+// @Param        projectId  path  string  true  "Project Id"
+// @Param        noteId  path  string  true  "Note Id"
+// @Param        If-Match  header  string  true  "Project version got from ETag"
+// @Param        resolve  body  model.NoteResolve  true  "Resolved flag"
+// @Success      200  {object}  map[string]any
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      404  {object}  error
+// @Failure      412  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/projects/{projectId}/notes/{noteId}/resolve [patch]
+func (h *Handler) ResolveNote(context *gin.Context) {
+	project, err := model.GetProject(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	userId, err := model.GetUserId(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+		return
+	}
+
+	noteId, err := uuid.Parse(context.Param("noteId"))
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var resolve model.NoteResolve
+	if err = context.BindJSON(&resolve); err != nil {
+		context.AbortWithStatusJSON(http.StatusBadRequest, "неверный формат данных")
+		return
+	}
+
+	note := model.Note{NoteId: noteId, ProjectId: project.ProjectId, Resolved: resolve.Resolved}
+	if resolve.Resolved {
+		resolvedAt := time.Now()
+		note.ResolvedBy = &userId
+		note.ResolvedAt = &resolvedAt
+	}
+
+	version, err := h.repo.ResolveNote(context.Request.Context(), project, note)
+	if err != nil {
+		context.AbortWithStatusJSON(repoErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+
+	context.Header("ETag", projectETag(version))
+	context.JSON(http.StatusOK, gin.H{"message": "статус заметки изменён"})
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"tiflo/model"
+)
+
+// sourceHeight returns height of project video, zero if it is unknown
+func sourceHeight(project model.Project) int {
+	if project.Probe == nil || project.Probe.Video == nil {
+		return 0
+	}
+
+	return project.Probe.Video.Height
+}
+
+// makePreviews makes editing proxy, HLS ladder and thumbnails of project media in background,
+// they are saved only if media of project is not replaced meanwhile. Failed preview is left empty.
+func (h *Handler) makePreviews(project model.Project) {
+	ctx := context.Background()
+
+	var err error
+	switch project.MediaType {
+	case model.MediaTypeVideo:
+		if project.ProxyPath, err = h.mediaService.MakeProxy(ctx, project.VideoPath); err != nil {
+			h.logger.Errorf("make proxy of project %s: %s", project.ProjectId, err)
+		}
+		if project.HlsPath, err = h.mediaService.MakeHLS(ctx, project.VideoPath, "", sourceHeight(project)); err != nil {
+			h.logger.Errorf("make HLS of project %s: %s", project.ProjectId, err)
+		}
+		if project.Probe != nil {
+			if project.ThumbnailsPath, err = h.mediaService.MakeThumbnails(ctx, project.VideoPath, *project.Probe); err != nil {
+				h.logger.Errorf("make thumbnails of project %s: %s", project.ProjectId, err)
+			}
+		}
+	case model.MediaTypeAudio:
+		if project.HlsPath, err = h.mediaService.MakeHLS(ctx, "", project.VideoPath, 0); err != nil {
+			h.logger.Errorf("make HLS of project %s: %s", project.ProjectId, err)
+		}
+	default:
+		return
+	}
+
+	// files of replaced media are left to garbage collector
+	if err = h.repo.SetMediaPreview(ctx, project); err != nil && !errors.Is(err, model.NotFound) {
+		h.logger.Errorf("save previews of project %s: %s", project.ProjectId, err)
+	}
+}
+
+// makeOutputPreview makes HLS ladder of described output in background. Described audio is longer than original
+// video, so only audio rendition is published, video is not muxed with it.
+func (h *Handler) makeOutputPreview(project model.Project, audioName string) {
+	ctx := context.Background()
+
+	var err error
+	switch project.MediaType {
+	case model.MediaTypeVideo, model.MediaTypeAudio:
+		project.OutputHlsPath, err = h.mediaService.MakeHLS(ctx, "", audioName, 0)
+	default:
+		return
+	}
+	if err != nil {
+		h.logger.Errorf("make output HLS of project %s: %s", project.ProjectId, err)
+		return
+	}
+
+	if err = h.repo.SetOutputPreview(ctx, project); err != nil && !errors.Is(err, model.NotFound) {
+		h.logger.Errorf("save output preview of project %s: %s", project.ProjectId, err)
+	}
+}
@@ -1,9 +1,14 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/google/uuid"
 	"net/http"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"tiflo/model"
 
 	"github.com/gin-gonic/gin"
@@ -11,12 +16,14 @@ import (
 
 // CreateProject godoc
 // @Summary      Create new user project
-// @Description  Create a  new project with default name
+// @Description  Create a  new project with default name. Project created from template inherits its voice, lint rules and lexicon
 // @Tags         Project
 // @Produce      json
+// @Param        templateId  query  string  false  "Id of user's template project"
 // @Success      200  {object}  model.Project
 // @Failure      400  {object}  error
 // @Failure      401  {object}  error
+// @Failure      404  {object}  error
 // @Failure      500  {object}  error
 // @Router       /api/projects/ [post]
 func (h *Handler) CreateProject(context *gin.Context) {
@@ -26,12 +33,24 @@ func (h *Handler) CreateProject(context *gin.Context) {
 		return
 	}
 
-	newProject, err := h.repo.CreateProject(context.Request.Context(), userId)
+	var newProject model.Project
+	if templateIdStr := context.Query("templateId"); templateIdStr != "" {
+		templateId, err := uuid.Parse(templateIdStr)
+		if err != nil {
+			context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+
+		newProject, err = h.repo.CreateProjectFromTemplate(context.Request.Context(), userId, templateId)
+	} else {
+		newProject, err = h.repo.CreateProject(context.Request.Context(), userId)
+	}
 	if err != nil {
-		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		context.AbortWithStatusJSON(repoErrorStatus(err), gin.H{"message": err.Error()})
 		return
 	}
 
+	h.fillMediaUrls(&newProject)
 	context.JSON(http.StatusOK, newProject)
 }
 
@@ -51,18 +70,13 @@ type ProjectUpdate struct {
 // @Failure      400  {object}  error
 // @Failure      401  {object}  error
 // @Failure      500  {object}  error
+// @Param        If-Match  header  string  true  "Project version got from ETag"
+// @Failure      412  {object}  error
 // @Router       /api/projects/{projectId} [patch]
 func (h *Handler) UpdateProjectName(context *gin.Context) {
-	projectIdStr := context.Param("projectId")
-	projectId, err := uuid.Parse(projectIdStr)
+	project, err := model.GetProject(context)
 	if err != nil {
-		context.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
-		return
-	}
-
-	userId, err := model.GetUserId(context)
-	if err != nil {
-		context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
 
@@ -73,15 +87,18 @@ func (h *Handler) UpdateProjectName(context *gin.Context) {
 		return
 	}
 
-	if err = h.repo.RenameProject(context.Request.Context(), model.Project{
-		ProjectId: projectId,
-		UserId:    userId,
+	version, err := h.repo.RenameProject(context.Request.Context(), model.Project{
+		ProjectId: project.ProjectId,
+		UserId:    project.UserId,
+		Version:   project.Version,
 		Name:      name.Name,
-	}); err != nil {
-		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+	})
+	if err != nil {
+		context.AbortWithStatusJSON(repoErrorStatus(err), gin.H{"message": err.Error()})
 		return
 	}
 
+	context.Header("ETag", projectETag(version))
 	context.JSON(http.StatusOK, gin.H{"message": "проект успешно переименован"})
 
 }
@@ -91,16 +108,25 @@ const (
 	previewTime  = "00:00:01.000"
 )
 
-var availableFormats = map[string]bool{
-	".jpeg": true,
-	".jpg":  true,
-	".mp4":  true,
-	".png":  true,
+// availableFormats maps extensions of uploaded files to media type of project
+var availableFormats = map[string]string{
+	".jpeg": model.MediaTypeImage,
+	".jpg":  model.MediaTypeImage,
+	".png":  model.MediaTypeImage,
+	".mp4":  model.MediaTypeVideo,
+	".mov":  model.MediaTypeVideo,
+	".mkv":  model.MediaTypeVideo,
+	".webm": model.MediaTypeVideo,
+	".wav":  model.MediaTypeAudio,
+	".mp3":  model.MediaTypeAudio,
+	".flac": model.MediaTypeAudio,
 }
 
+const wrongFormatMessage = "Неверный формат файла, доступны: .jpeg, .jpg, .png, .mp4, .mov, .mkv, .webm, .wav, .mp3, .flac"
+
 // UploadMedia godoc
 // @Summary      Upload media file for project
-// @Description  Uploads a media file to the server
+// @Description  Uploads image (.jpeg, .jpg, .png), video (.mp4, .mov, .mkv, .webm) or audio (.wav, .mp3, .flac). Video which can not be played in browser is transcoded to mp4, audio-only project gets timeline without frames
 // @Tags         Project
 // @Accept       mpfd
 // @Produce      json
@@ -108,18 +134,16 @@ var availableFormats = map[string]bool{
 // @Param        projectId  path  string  true  "Project Id"
 // @Success      200 {object} map[string]any "Successfully uploaded"
 // @Failure      500 {object} map[string]any "Failed to save file"
+// @Param        If-Match  header  string  true  "Project version got from ETag"
+// @Failure      412 {object} map[string]any "Project was changed"
+// @Failure      423 {object} map[string]any "Project is being edited"
+// @Failure      413 {object} map[string]any "File is too large"
+// @Failure      422 {object} map[string]any "File content is not supported or exceeds limits"
 // @Router       /api/projects/{projectId}/media [post]
 func (h *Handler) UploadMedia(context *gin.Context) {
-	projectIdStr := context.Param("projectId")
-	projectId, err := uuid.Parse(projectIdStr)
-	if err != nil {
-		context.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
-		return
-	}
-
-	userId, err := model.GetUserId(context)
+	project, err := model.GetProject(context)
 	if err != nil {
-		context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
 
@@ -128,74 +152,125 @@ func (h *Handler) UploadMedia(context *gin.Context) {
 	filename := uuid.New()
 
 	for _, file := range files {
-		extension := filepath.Ext(file.Filename)
-		if val, ok := availableFormats[extension]; !ok || !val {
-			h.logger.Printf("Wrong file extension: %v", val)
-			context.String(http.StatusBadRequest, "Неверный формат файла, доступны: .jpeg, .jpg, .png, .mp4")
+		extension := strings.ToLower(filepath.Ext(file.Filename))
+		if _, ok := availableFormats[extension]; !ok {
+			h.logger.Printf("Wrong file extension: %v", extension)
+			context.String(http.StatusBadRequest, wrongFormatMessage)
+			return
+		}
+
+		if h.mediaLimits.MaxSize > 0 && file.Size > h.mediaLimits.MaxSize {
+			context.String(http.StatusRequestEntityTooLarge, "Файл слишком большой")
 			return
 		}
 
-		if err = context.SaveUploadedFile(file, PathForMedia+filename.String()+extension); err != nil {
+		if err = h.saveUploadedFile(context.Request.Context(), file, filename.String()+extension); err != nil {
 			h.logger.Printf("Failed to save file: %s", err)
 			context.String(http.StatusInternalServerError, "Failed to save file")
 			return
 		}
 
-		var audio []model.AudioPart
-		var project model.Project
+		if project.Version, err = h.processMedia(context.Request.Context(), project, filename, extension,
+			model.GetLockToken(context)); err != nil {
+			context.String(repoErrorStatus(err), err.Error())
+			return
+		}
+	}
 
-		if extension == ".mp4" {
-			err = h.mediaService.GetAudioFromVideo(filename.String(), extension)
-			if err != nil {
-				context.String(http.StatusInternalServerError, "Failed to get audio from video")
-				return
-			}
+	context.Header("ETag", projectETag(project.Version))
+	context.JSON(http.StatusOK, gin.H{"message": "File uploaded successfully"})
+}
 
-			_, durationInt, err := h.mediaService.GetAudioDurationWav(filename.String() + ".wav")
-			if err != nil {
-				context.String(http.StatusInternalServerError, "Failed to get time duration")
-				return
+// processMedia checks content of uploaded file, makes its audio track and preview and attaches it to project,
+// new version of project is returned. File which does not pass the check is deleted.
+// Project is changed only if fencing token of edit lock is still valid.
+// Original video is kept, the one which can not be played in browser is played from proxy made in background.
+func (h *Handler) processMedia(ctx context.Context, project model.Project, filename uuid.UUID,
+	extension string, fencingToken int64) (int64, error) {
+	var media model.Project
+	var durationInt int64
+
+	name := filename.String() + extension
+	media.MediaType = availableFormats[extension]
+
+	probe, err := h.mediaService.Probe(ctx, name)
+	if err == nil {
+		err = h.mediaLimits.Check(probe, media.MediaType)
+	}
+	if err != nil {
+		if errors.Is(err, model.InvalidMedia) {
+			if err := h.storage.Delete(ctx, name); err != nil {
+				h.logger.Error(err)
 			}
+		}
+		return 0, err
+	}
+	media.Probe = &probe
 
-			audio = append(audio, model.AudioPart{
-				PartId:    uuid.New(),
-				ProjectId: projectId,
-				Start:     0,
-				Duration:  durationInt,
-				Text:      "",
-				Path:      filename.String() + ".wav",
-			})
-
-			project.AudioParts = audio
-			project.AudioPath = filename.String() + ".wav"
-
-			frameName, err := h.mediaService.ExtractFrame(PathForMedia+filename.String()+extension, previewTime)
-			if err != nil {
-				context.String(http.StatusInternalServerError, "Failed to get preview")
-				return
+	switch media.MediaType {
+	case model.MediaTypeVideo:
+		if err = h.mediaService.GetAudioFromVideo(ctx, filename.String(), extension); err != nil {
+			return 0, fmt.Errorf("Failed to get audio from video: %w", err)
+		}
+		media.AudioPath = filename.String() + ".wav"
+
+		if _, durationInt, err = h.mediaService.GetAudioDurationWav(ctx, media.AudioPath); err != nil {
+			return 0, fmt.Errorf("Failed to get time duration: %w", err)
+		}
+
+		if media.ImagePath, err = h.mediaService.ExtractFrame(ctx, name, previewTime); err != nil {
+			return 0, fmt.Errorf("Failed to get preview: %w", err)
+		}
+	case model.MediaTypeAudio:
+		// audio-only project has timeline, but no frames
+		media.AudioPath = name
+		if extension != ".wav" {
+			if media.AudioPath, err = h.mediaService.GetAudioFromAudio(ctx, name); err != nil {
+				return 0, fmt.Errorf("Failed to convert audio: %w", err)
 			}
+		}
 
-			project.ImagePath = frameName
+		if extension == ".mp3" {
+			_, durationInt, err = h.mediaService.GetAudioDurationMp3(ctx, name)
 		} else {
-			project.ImagePath = filename.String() + extension
+			_, durationInt, err = h.mediaService.GetAudioDurationWav(ctx, media.AudioPath)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("Failed to get time duration: %w", err)
 		}
+	default:
+		media.ImagePath = name
+	}
 
-		project.ProjectId = projectId
-		project.UserId = userId
-		project.VideoPath = filename.String() + extension
+	if media.AudioPath != "" {
+		media.AudioParts = []model.AudioPart{{
+			PartId:    uuid.New(),
+			ProjectId: project.ProjectId,
+			Start:     0,
+			Duration:  durationInt,
+			Text:      "",
+			Path:      media.AudioPath,
+		}}
+	}
 
-		if err = h.repo.UploadMedia(context.Request.Context(), project); err != nil {
-			context.String(http.StatusInternalServerError, "Failed to save file")
-			return
-		}
+	media.ProjectId = project.ProjectId
+	media.UserId = project.UserId
+	media.Version = project.Version
+	media.VideoPath = name
+
+	version, err := h.repo.UploadMedia(ctx, media, fencingToken)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to save file: %w", err)
 	}
 
-	context.JSON(http.StatusOK, gin.H{"message": "File uploaded successfully"})
+	go h.makePreviews(media)
+
+	return version, nil
 }
 
 // DeleteProject godoc
 // @Summary      Delete project
-// @Description  Delete project
+// @Description  Move project to trash, it can be restored until retention period is over
 // @Tags         Project
 // @Produce      json
 // @Param        projectId  path  string  true  "Project Id"
@@ -203,70 +278,65 @@ func (h *Handler) UploadMedia(context *gin.Context) {
 // @Failure      400  {object}  error
 // @Failure      401  {object}  error
 // @Failure      500  {object}  error
+// @Param        If-Match  header  string  true  "Project version got from ETag"
+// @Failure      412  {object}  error
 // @Router       /api/projects/{projectId} [delete]
 func (h *Handler) DeleteProject(context *gin.Context) {
-	projectIdStr := context.Param("projectId")
-	projectId, err := uuid.Parse(projectIdStr)
-	if err != nil {
-		context.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
-		return
-	}
-
-	userId, err := model.GetUserId(context)
+	project, err := model.GetProject(context)
 	if err != nil {
-		context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
 
-	err = h.repo.DeleteProject(context.Request.Context(), model.Project{ProjectId: projectId, UserId: userId})
+	err = h.repo.DeleteProject(context.Request.Context(), project)
 	if err != nil {
-		context.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
+		context.AbortWithStatusJSON(repoErrorStatus(err), err.Error())
 		return
 	}
 
-	context.JSON(http.StatusOK, gin.H{"message": "Project removed successfully"})
+	context.JSON(http.StatusOK, gin.H{"message": "Project moved to trash"})
 }
 
 // GetProjectInfo godoc
 // @Summary      Get project info
-// @Description  Get project name, path to media and audio parts
+// @Description  Get project name, URLs of media, editing proxy, HLS playlists and thumbnails index, and audio parts
 // @Tags         Project
 // @Produce      json
 // @Param        projectId  path  string  true  "Project Id"
 // @Success      200  {object}  model.Project
+// @Header       200  {string}  ETag  "Project version"
 // @Failure      400  {object}  error
 // @Failure      401  {object}  error
 // @Failure      500  {object}  error
 // @Router       /api/projects/{projectId} [get]
 func (h *Handler) GetProjectInfo(context *gin.Context) {
-	projectIdStr := context.Param("projectId")
-	projectId, err := uuid.Parse(projectIdStr)
-	if err != nil {
-		context.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
-		return
-	}
-
-	userId, err := model.GetUserId(context)
-	if err != nil {
-		context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
-		return
-	}
-
-	project, err := h.repo.GetProject(context.Request.Context(), model.Project{ProjectId: projectId, UserId: userId})
+	project, err := model.GetProject(context)
 	if err != nil {
 		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
 
+	h.fillMediaUrls(&project)
+
+	context.Header("ETag", projectETag(project.Version))
 	context.JSON(http.StatusOK, project)
 }
 
 // GetProjects godoc
-// @Summary      Get all user' projects info
-// @Description  Get all user' projects as an array
+// @Summary      Get user' projects
+// @Description  Get page of user' projects. Next page is requested with nextCursor of previous one and the same sort and order
 // @Tags         Project
 // @Produce      json
-// @Success      200  {object}  []model.Project
+// @Param        limit  query  int  false  "Page size, 20 by default, 100 at most"
+// @Param        cursor  query  string  false  "nextCursor from previous page"
+// @Param        sort  query  string  false  "Sort field: created (default), name, updated"
+// @Param        order  query  string  false  "asc or desc, by default desc for dates and asc for name"
+// @Param        mediaType  query  string  false  "Filter by media type: video, image, audio, none"
+// @Param        status  query  string  false  "Filter by status: new, draft, in_review, ready"
+// @Param        search  query  string  false  "Search in project name"
+// @Param        template  query  bool  false  "Return only templates or only ordinary projects"
+// @Param        summary  query  bool  false  "Return projects without audio parts"
+// @Success      200  {object}  model.ProjectList
 // @Failure      400  {object}  error
 // @Failure      401  {object}  error
 // @Failure      500  {object}  error
@@ -278,52 +348,168 @@ func (h *Handler) GetProjects(context *gin.Context) {
 		return
 	}
 
-	projects, err := h.repo.GetProjectsList(context.Request.Context(), userId)
+	params, err := parseProjectListParams(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	params.UserId = userId
+
+	projects, err := h.repo.GetProjectsList(context.Request.Context(), params)
 	if err != nil {
+		if err == model.InvalidCursor {
+			context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
 		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
 
+	for i := range projects.Projects {
+		h.fillMediaUrls(&projects.Projects[i])
+	}
+
 	context.JSON(http.StatusOK, projects)
 }
 
+var projectStatuses = map[string]bool{
+	model.ProjectStatusNew:      true,
+	model.ProjectStatusDraft:    true,
+	model.ProjectStatusInReview: true,
+	model.ProjectStatusReady:    true,
+}
+
+func parseProjectListParams(context *gin.Context) (model.ProjectListParams, error) {
+	params := model.ProjectListParams{
+		Sort:    context.DefaultQuery("sort", model.ProjectSortCreated),
+		Search:  strings.TrimSpace(context.Query("search")),
+		Limit:   model.ProjectListDefaultLimit,
+		Summary: context.Query("summary") == "true",
+	}
+
+	if limit := context.Query("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 {
+			return params, errors.New("неверный размер страницы")
+		}
+		params.Limit = value
+		if params.Limit > model.ProjectListMaxLimit {
+			params.Limit = model.ProjectListMaxLimit
+		}
+	}
+
+	switch params.Sort {
+	case model.ProjectSortCreated, model.ProjectSortUpdated:
+		params.Desc = true
+	case model.ProjectSortName:
+	default:
+		return params, errors.New("неизвестное поле сортировки")
+	}
+
+	switch context.Query("order") {
+	case "":
+	case "asc":
+		params.Desc = false
+	case "desc":
+		params.Desc = true
+	default:
+		return params, errors.New("неизвестный порядок сортировки")
+	}
+
+	switch mediaType := context.Query("mediaType"); mediaType {
+	case "":
+	case "none":
+		none := model.MediaTypeNone
+		params.MediaType = &none
+	case model.MediaTypeVideo, model.MediaTypeImage, model.MediaTypeAudio:
+		params.MediaType = &mediaType
+	default:
+		return params, errors.New("неизвестный тип медиа")
+	}
+
+	if template := context.Query("template"); template != "" {
+		value, err := strconv.ParseBool(template)
+		if err != nil {
+			return params, errors.New("неверный фильтр шаблонов")
+		}
+		params.Template = &value
+	}
+
+	if status := context.Query("status"); status != "" {
+		if !projectStatuses[status] {
+			return params, errors.New("неизвестный статус проекта")
+		}
+		params.Status = status
+	}
+
+	if cursor := context.Query("cursor"); cursor != "" {
+		decoded, err := model.DecodeProjectCursor(cursor)
+		if err != nil {
+			return params, err
+		}
+		if decoded.Sort != params.Sort || decoded.Desc != params.Desc {
+			return params, model.InvalidCursor
+		}
+		params.Cursor = &decoded
+	}
+
+	return params, nil
+}
+
 // ConcatAudio godoc
 // @Summary      Get final audio
-// @Description  Get path for audio file got from all audio parts
+// @Description  Get path for audio file got from all audio parts, audio-only HLS of it is made in background
 // @Tags         Audio
 // @Param        projectId  path  string  true  "Project Id"
+// @Param        If-Match  header  string  true  "Project version got from ETag"
+// @Param        force  query  bool  false  "Render even if some descriptions are not approved"
 // @Produce      json
 // @Success      200  {object}  map[string]any
+// @Header       200  {int}  X-Not-Approved  "Number of descriptions which are not approved, set on forced render"
 // @Failure      400  {object}  error
 // @Failure      401  {object}  error
+// @Failure      409  {object}  model.DeliveryReadiness
+// @Failure      412  {object}  error
 // @Failure      500  {object}  error
 // @Router       /api/projects/{projectId}/audio [post]
 func (h *Handler) ConcatAudio(context *gin.Context) {
-	userId, err := model.GetUserId(context)
+	project, err := model.GetProject(context)
 	if err != nil {
-		context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
 
-	projectIdStr := context.Param("projectId")
-	projectId, err := uuid.Parse(projectIdStr)
-	if err != nil {
-		h.logger.Error(err)
-		context.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+	readiness, ok := h.checkDeliveryReadiness(context, project)
+	if !ok {
 		return
 	}
 
-	project, err := h.repo.GetProject(context.Request.Context(), model.Project{ProjectId: projectId, UserId: userId})
+	path, err := h.mediaService.ConcatAudio(context.Request.Context(), project.AudioParts)
 	if err != nil {
 		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
 
-	path, err := h.mediaService.ConcatAudio(project.AudioParts)
+	// rendered audio is kept as project output, otherwise garbage collector deletes it
+	project.OutputPath = path
+	version, err := h.repo.SetOutput(context.Request.Context(), project)
 	if err != nil {
-		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		if errors.Is(err, model.NotFound) {
+			context.AbortWithStatusJSON(http.StatusConflict, gin.H{"message": "медиа проекта заменено во время сборки"})
+			return
+		}
+		context.AbortWithStatusJSON(repoErrorStatus(err), gin.H{"message": err.Error()})
 		return
 	}
 
-	context.JSON(http.StatusOK, gin.H{"path": path})
+	go h.makeOutputPreview(project, path)
+
+	response := gin.H{"path": path, "url": h.mediaUrl(path)}
+	if !readiness.Ready {
+		response["warning"] = "не все описания одобрены"
+		response["notApproved"] = readiness.NotApproved
+	}
+
+	context.Header("ETag", projectETag(version))
+	context.JSON(http.StatusOK, response)
 }
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"sort"
+	"tiflo/model"
+)
+
+func bindReplacer(context *gin.Context) (*model.Replacer, bool) {
+	var request model.ReplaceRequest
+	if err := context.BindJSON(&request); err != nil {
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "неверный формат данных"})
+		return nil, false
+	}
+
+	replacer, err := model.NewReplacer(request)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return nil, false
+	}
+
+	return replacer, true
+}
+
+// checkEmptied rejects replace which leaves descriptions empty
+func checkEmptied(context *gin.Context, previews []model.ReplacePreview) bool {
+	if emptied := model.EmptiedParts(previews); len(emptied) > 0 {
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message": "после замены описания станут пустыми",
+			"parts":   emptied,
+		})
+		return false
+	}
+
+	return true
+}
+
+// PreviewReplace godoc
+// @Summary      Preview find/replace
+// @Description  Show descriptions of project which would be changed by find/replace
+// @Tags         Audio part
+// @Accept       json
+// @Produce      json
+// @Param        projectId  path  string  true  "Project Id"
+// @Param        request  body  model.ReplaceRequest  true  "What to find and replace, mode is literal, regex or word"
+// @Success      200  {object}  []model.ReplacePreview
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/projects/{projectId}/replace/preview [post]
+func (h *Handler) PreviewReplace(context *gin.Context) {
+	project, err := model.GetProject(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	replacer, ok := bindReplacer(context)
+	if !ok {
+		return
+	}
+
+	sort.SliceStable(project.AudioParts, func(i, j int) bool {
+		return project.AudioParts[i].Start < project.AudioParts[j].Start
+	})
+
+	previews := replacer.Preview(project)
+	if !checkEmptied(context, previews) {
+		return
+	}
+
+	context.JSON(http.StatusOK, previews)
+}
+
+// ReplaceText godoc
+// @Summary      Find/replace in descriptions
+// @Description  Replace text in all descriptions of project, changed parts are re-voiced and later parts are shifted by new durations
+// @Tags         Audio part
+// @Accept       json
+// @Produce      json
+// @Param        projectId  path  string  true  "Project Id"
+// @Param        If-Match  header  string  true  "Project version got from ETag"
+// @Param        request  body  model.ReplaceRequest  true  "What to find and replace, mode is literal, regex or word"
+// @Success      200  {object}  map[string]any
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      412  {object}  error
+// @Failure      423  {object}  map[string]any
+// @Failure      500  {object}  error
+// @Router       /api/projects/{projectId}/replace [post]
+func (h *Handler) ReplaceText(context *gin.Context) {
+	project, err := model.GetProject(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	replacer, ok := bindReplacer(context)
+	if !ok {
+		return
+	}
+
+	sort.SliceStable(project.AudioParts, func(i, j int) bool {
+		return project.AudioParts[i].Start < project.AudioParts[j].Start
+	})
+
+	previews := replacer.Preview(project)
+	if !checkEmptied(context, previews) {
+		return
+	}
+
+	changes := make(map[uuid.UUID]string)
+	for _, preview := range previews {
+		changes[preview.PartId] = preview.After
+	}
+
+	if len(changes) == 0 {
+		context.Header("ETag", projectETag(project.Version))
+		context.JSON(http.StatusOK, gin.H{"message": "совпадений не найдено", "changed": []uuid.UUID{}})
+		return
+	}
+
+	// shift is accumulated change of duration of all re-voiced parts before current one
+	var shift int64
+	changed := make([]uuid.UUID, 0, len(changes))
+	warnings := make([]model.LintWarning, 0)
+	lintConfig := h.projectLintConfig(project)
+	updated := make([]model.AudioPart, 0, len(project.AudioParts))
+	for _, part := range project.AudioParts {
+		part.Start += shift
+
+		if text, ok := changes[part.PartId]; ok {
+			path, voiceInput, err := h.voiceText(context.Request.Context(), project, text)
+			if err != nil {
+				h.logger.Error(err)
+				context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+				return
+			}
+
+			_, durationInt, err := h.mediaService.GetAudioDurationWav(context.Request.Context(), path)
+			if err != nil {
+				h.logger.Error(err)
+				context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+				return
+			}
+
+			shift += durationInt - part.Duration
+			part.Duration = durationInt
+			part.Text = text
+			part.Path = path
+			part.VoiceInput = voiceInput
+			part.Voice = project.Voice
+			// changed description has to be reviewed again
+			part.Status = model.PartStatusDraft
+			part.ReviewerId = nil
+			part.Reviewed = nil
+
+			changed = append(changed, part.PartId)
+			warnings = append(warnings, h.linter.LintPart(lintConfig, part)...)
+		}
+
+		updated = append(updated, part)
+	}
+
+	version, err := h.repo.UpdateTimeline(context.Request.Context(), project.UserId, model.TimelineUpdate{
+		ProjectId:    project.ProjectId,
+		Version:      project.Version,
+		FencingToken: model.GetLockToken(context),
+		Updated:      updated,
+	})
+	if err != nil {
+		h.logger.Error(err)
+		context.AbortWithStatusJSON(repoErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+
+	context.Header("ETag", projectETag(version))
+	context.JSON(http.StatusOK, gin.H{
+		"message":  "successfully replaced",
+		"changed":  changed,
+		"warnings": warnings,
+	})
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"strconv"
+	"tiflo/model"
+	"time"
+)
+
+// ChangeAudioPartStatus godoc
+// @Summary      Change review status of audio part
+// @Description  Move audio part description to draft, needs_review, approved or rejected status.
+// @Description  Caller is recorded as reviewer, for now only project owner has access to project and reviews it.
+// @Tags         Review
+// @Accept       json
+// @Produce      json
+// @Param        projectId  path  string  true  "Project Id"
+// @Param        audioPartId  path  string  true  "Audio part Id"
+// @Param        If-Match  header  string  true  "Project version got from ETag"
+// @Param        status  body  model.PartStatusUpdate  true  "New status"
+// @Success      200  {object}  model.AudioPart
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      404  {object}  error
+// @Failure      409  {object}  error
+// @Failure      412  {object}  error
+// @Failure      423  {object}  map[string]any
+// @Failure      500  {object}  error
+// @Router       /api/projects/{projectId}/audio-part/{audioPartId}/status [patch]
+func (h *Handler) ChangeAudioPartStatus(context *gin.Context) {
+	project, err := model.GetProject(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	reviewerId, err := model.GetUserId(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+		return
+	}
+
+	audioPartId, err := uuid.Parse(context.Param("audioPartId"))
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var statusUpdate model.PartStatusUpdate
+	if err = context.BindJSON(&statusUpdate); err != nil {
+		context.AbortWithStatusJSON(http.StatusBadRequest, "неверный формат данных")
+		return
+	}
+
+	if !model.IsPartStatus(statusUpdate.Status) {
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "неизвестный статус: " + statusUpdate.Status})
+		return
+	}
+
+	var part model.AudioPart
+	var found bool
+	for _, v := range project.AudioParts {
+		if v.PartId == audioPartId {
+			part, found = v, true
+			break
+		}
+	}
+	if !found {
+		context.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": model.NotFound.Error()})
+		return
+	}
+
+	if !model.CanChangePartStatus(part.Status, statusUpdate.Status) {
+		context.AbortWithStatusJSON(http.StatusConflict, gin.H{
+			"message": "недопустимая смена статуса: " + part.Status + " -> " + statusUpdate.Status,
+		})
+		return
+	}
+
+	reviewed := time.Now()
+	part.Status = statusUpdate.Status
+	part.ReviewerId = &reviewerId
+	part.Reviewed = &reviewed
+
+	version, err := h.repo.UpdateAudioPartStatus(context.Request.Context(), project, part, model.GetLockToken(context))
+	if err != nil {
+		context.AbortWithStatusJSON(repoErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+
+	context.Header("ETag", projectETag(version))
+	context.JSON(http.StatusOK, part)
+}
+
+// GetDeliveryReadiness godoc
+// @Summary      Check if project is ready for delivery
+// @Description  Project is ready when all its descriptions are approved
+// @Tags         Review
+// @Produce      json
+// @Param        projectId  path  string  true  "Project Id"
+// @Success      200  {object}  model.DeliveryReadiness
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/projects/{projectId}/readiness [get]
+func (h *Handler) GetDeliveryReadiness(context *gin.Context) {
+	project, err := model.GetProject(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, model.GetDeliveryReadiness(project))
+}
+
+// checkDeliveryReadiness refuses render and export of project with descriptions which are not approved,
+// unless force=true is passed. Forced delivery is marked with NotApprovedHeader.
+func (h *Handler) checkDeliveryReadiness(context *gin.Context, project model.Project) (model.DeliveryReadiness, bool) {
+	readiness := model.GetDeliveryReadiness(project)
+	if readiness.Ready {
+		return readiness, true
+	}
+
+	if context.Query("force") != "true" {
+		context.AbortWithStatusJSON(http.StatusConflict, readiness)
+		return readiness, false
+	}
+
+	context.Header(model.NotApprovedHeader, strconv.Itoa(len(readiness.NotApproved)))
+	return readiness, true
+}
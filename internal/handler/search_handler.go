@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"tiflo/internal/repository"
+	"tiflo/model"
+)
+
+var snippetReplacer = strings.NewReplacer(repository.SnippetStart, "<mark>", repository.SnippetStop, "</mark>")
+
+// Search godoc
+// @Summary      Search descriptions
+// @Description  Full-text search over descriptions of all user's projects
+// @Tags         Search
+// @Produce      json
+// @Param        q  query  string  true  "Search query, supports quotes, OR and -word"
+// @Param        lang  query  string  false  "ru or en, both languages are used by default"
+// @Param        projectId  query  string  false  "Search only in this project"
+// @Param        limit  query  int  false  "Page size, 20 by default, 100 at most"
+// @Param        offset  query  int  false  "Offset"
+// @Success      200  {object}  []model.SearchHit
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/search [get]
+func (h *Handler) Search(context *gin.Context) {
+	userId, err := model.GetUserId(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+		return
+	}
+
+	params := model.SearchParams{
+		UserId:   userId,
+		Query:    strings.TrimSpace(context.Query("q")),
+		Language: context.Query("lang"),
+		Limit:    model.SearchDefaultLimit,
+	}
+
+	if params.Query == "" {
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "пустой поисковый запрос"})
+		return
+	}
+
+	if params.Language != "" && params.Language != model.SearchLanguageRussian && params.Language != model.SearchLanguageEnglish {
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "неизвестный язык поиска"})
+		return
+	}
+
+	if projectIdStr := context.Query("projectId"); projectIdStr != "" {
+		projectId, err := uuid.Parse(projectIdStr)
+		if err != nil {
+			context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+		params.ProjectId = &projectId
+	}
+
+	if limit := context.Query("limit"); limit != "" {
+		params.Limit, err = strconv.Atoi(limit)
+		if err != nil || params.Limit < 1 {
+			context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "неверный размер страницы"})
+			return
+		}
+		if params.Limit > model.SearchMaxLimit {
+			params.Limit = model.SearchMaxLimit
+		}
+	}
+
+	if offset := context.Query("offset"); offset != "" {
+		params.Offset, err = strconv.Atoi(offset)
+		if err != nil || params.Offset < 0 {
+			context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "неверное смещение"})
+			return
+		}
+	}
+
+	hits, err := h.repo.SearchAudioParts(context.Request.Context(), params)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	for i := range hits {
+		hits[i].Time = h.mediaService.ConvertTimeToString(hits[i].Start)
+		// description is user input, so it is escaped before matches are marked
+		hits[i].Snippet = snippetReplacer.Replace(html.EscapeString(hits[i].Snippet))
+		// descriptions are inserted into original audio, so frame is taken at video time of hit
+		hits[i].PreviewUrl = fmt.Sprintf("/api/projects/%s/frame?start=%d", hits[i].ProjectId, hits[i].VideoTime)
+	}
+
+	context.JSON(http.StatusOK, hits)
+}
+
+// GetFrame godoc
+// @Summary      Get frame preview
+// @Description  Get frame of project video at given time, for image projects the image itself is returned
+// @Tags         Search
+// @Produce      png
+// @Param        projectId  path  string  true  "Project Id"
+// @Param        start  query  int  true  "Video time in tenths of a second, without durations of descriptions"
+// @Success      200  {file}  file
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      404  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/projects/{projectId}/frame [get]
+func (h *Handler) GetFrame(context *gin.Context) {
+	project, err := model.GetProject(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	start, err := strconv.ParseInt(context.Query("start"), 10, 64)
+	if err != nil || start < 0 {
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "неверное время кадра"})
+		return
+	}
+
+	switch project.MediaType {
+	case model.MediaTypeImage:
+		h.serveMedia(context, project.ImagePath)
+		return
+	case model.MediaTypeVideo:
+	default:
+		context.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "в проекте нет видео"})
+		return
+	}
+
+	frameName, err := h.mediaService.ExtractFrame(context.Request.Context(), project.VideoPath, h.mediaService.ConvertTimeToString(start))
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	defer h.storage.Delete(context.Request.Context(), frameName)
+
+	h.serveMedia(context, frameName)
+}
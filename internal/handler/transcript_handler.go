@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"tiflo/model"
+	"tiflo/pkg/grpc/client"
+	"tiflo/pkg/transcript"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+)
+
+type TranscriptConfig struct {
+	// InputDir is directory where speech2text service reads audio from
+	InputDir string
+	// Language of dialogue, empty language is detected by service
+	Language string
+}
+
+func initTranscriptConfig(vp *viper.Viper) TranscriptConfig {
+	config := TranscriptConfig{
+		InputDir: PathForMedia,
+		Language: vp.GetString("python.speech2text.language"),
+	}
+
+	if inputDir := vp.GetString("python.speech2text.inputDir"); inputDir != "" {
+		config.InputDir = inputDir
+	}
+
+	return config
+}
+
+// CreateTranscript godoc
+// @Summary      Transcribe dialogue
+// @Description  Recognise dialogue of project audio, previous transcript is replaced
+// @Tags         Transcript
+// @Produce      json
+// @Param        projectId  path  string  true  "Project Id"
+// @Param        If-Match  header  string  true  "Project version got from ETag"
+// @Success      200  {array}  model.TranscriptSegment
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      409  {object}  error
+// @Failure      412  {object}  error
+// @Failure      423  {object}  map[string]any
+// @Failure      500  {object}  error
+// @Failure      501  {object}  error
+// @Router       /api/projects/{projectId}/transcript [post]
+func (h *Handler) CreateTranscript(context *gin.Context) {
+	project, err := model.GetProject(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	if project.AudioPath == "" {
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "в проекте нет аудио"})
+		return
+	}
+
+	paths, cleanup, err := h.exposeFiles(context.Request.Context(), h.transcriptConfig.InputDir, []string{project.AudioPath})
+	if err != nil {
+		h.logger.Error(err)
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	defer cleanup()
+
+	segments, err := h.pythonClient.Transcribe(context.Request.Context(), paths[0], h.transcriptConfig.Language)
+	if errors.Is(err, client.ServiceNotSet) {
+		context.AbortWithStatusJSON(http.StatusNotImplemented, gin.H{"message": "распознавание речи не настроено"})
+		return
+	}
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	segments, version, err := h.repo.SetTranscript(context.Request.Context(), project, segments,
+		model.GetLockToken(context))
+	if errors.Is(err, model.NotFound) {
+		context.AbortWithStatusJSON(http.StatusConflict, gin.H{"message": "медиа проекта заменено во время распознавания"})
+		return
+	}
+	if err != nil {
+		context.AbortWithStatusJSON(repoErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+
+	context.Header("ETag", projectETag(version))
+	context.JSON(http.StatusOK, segments)
+}
+
+// GetTranscript godoc
+// @Summary      Get transcript
+// @Description  Timed dialogue segments of project audio
+// @Tags         Transcript
+// @Produce      json
+// @Param        projectId  path  string  true  "Project Id"
+// @Success      200  {array}  model.TranscriptSegment
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/projects/{projectId}/transcript [get]
+func (h *Handler) GetTranscript(context *gin.Context) {
+	project, err := model.GetProject(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	segments, err := h.repo.GetTranscript(context.Request.Context(), project.ProjectId)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, segments)
+}
+
+// ExportTranscript godoc
+// @Summary      Export described transcript
+// @Description  Dialogue and descriptions interleaved by time in one accessible document
+// @Tags         Transcript
+// @Produce      html
+// @Produce      plain
+// @Param        projectId  path  string  true  "Project Id"
+// @Param        format  query  string  false  "html (default) or txt"
+// @Param        force  query  bool  false  "Export even if some descriptions are not approved"
+// @Success      200  {file}  file
+// @Header       200  {int}  X-Not-Approved  "Number of descriptions which are not approved, set on forced export"
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      409  {object}  model.DeliveryReadiness
+// @Failure      500  {object}  error
+// @Router       /api/projects/{projectId}/transcript/export [get]
+func (h *Handler) ExportTranscript(context *gin.Context) {
+	project, err := model.GetProject(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	format := context.DefaultQuery("format", model.TranscriptFormatHTML)
+	contentType := "text/html; charset=utf-8"
+	write := transcript.WriteHTML
+	switch format {
+	case model.TranscriptFormatHTML:
+	case model.TranscriptFormatText:
+		contentType = "text/plain; charset=utf-8"
+		write = transcript.WriteText
+	default:
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "неверный формат, доступны: html, txt"})
+		return
+	}
+
+	if _, ok := h.checkDeliveryReadiness(context, project); !ok {
+		return
+	}
+
+	segments, err := h.repo.GetTranscript(context.Request.Context(), project.ProjectId)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	title := project.Name
+	if title == "" {
+		title = "Транскрипт"
+	}
+
+	context.Header("Content-Type", contentType)
+	context.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, project.ProjectId, format))
+	context.Status(http.StatusOK)
+
+	if err = write(context.Writer, title, transcript.Build(segments, project.AudioParts)); err != nil {
+		h.logger.Error("export transcript: ", err)
+		context.Abort()
+	}
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"tiflo/model"
+	"time"
+)
+
+type TrashProject struct {
+	model.Project
+	// PurgeAt is time when project is deleted permanently
+	PurgeAt time.Time `json:"purgeAt"`
+}
+
+// GetTrash godoc
+// @Summary      Get deleted projects
+// @Description  Get user' projects in trash, they are deleted permanently at purgeAt
+// @Tags         Project
+// @Produce      json
+// @Success      200  {object}  []TrashProject
+// @Failure      401  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/projects/trash [get]
+func (h *Handler) GetTrash(context *gin.Context) {
+	userId, err := model.GetUserId(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+		return
+	}
+
+	projects, err := h.repo.GetTrash(context.Request.Context(), userId)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	trash := make([]TrashProject, 0, len(projects))
+	for _, project := range projects {
+		h.fillMediaUrls(&project)
+		trash = append(trash, TrashProject{
+			Project: project,
+			PurgeAt: project.DeletedAt.Add(h.trashConfig.Retention),
+		})
+	}
+
+	context.JSON(http.StatusOK, trash)
+}
+
+// RestoreProject godoc
+// @Summary      Restore deleted project
+// @Description  Take project out of trash
+// @Tags         Project
+// @Produce      json
+// @Param        projectId  path  string  true  "Project Id"
+// @Success      200  {object}  map[string]any
+// @Header       200  {string}  ETag  "Project version"
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      404  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/projects/trash/{projectId}/restore [post]
+func (h *Handler) RestoreProject(context *gin.Context) {
+	userId, err := model.GetUserId(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+		return
+	}
+
+	projectId, err := uuid.Parse(context.Param("projectId"))
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	version, err := h.repo.RestoreProject(context.Request.Context(), userId, projectId)
+	if err != nil {
+		context.AbortWithStatusJSON(repoErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+
+	context.Header("ETag", projectETag(version))
+	context.JSON(http.StatusOK, gin.H{"message": "проект восстановлен"})
+}
@@ -0,0 +1,436 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"tiflo/model"
+	"tiflo/pkg/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+const tusContentType = "application/offset+octet-stream"
+
+type UploadConfig struct {
+	// Expiration is how long unfinished upload is kept after its last chunk
+	Expiration time.Duration
+	// TempDir is where chunk is staged until it is put to storage
+	TempDir string
+}
+
+func initUploadConfig(vp *viper.Viper, tempDir string) UploadConfig {
+	config := UploadConfig{
+		Expiration: 24 * time.Hour,
+		TempDir:    tempDir,
+	}
+
+	if expiration := vp.GetDuration("upload.expiration"); expiration > 0 {
+		config.Expiration = expiration
+	}
+
+	return config
+}
+
+// parseUploadMetadata decodes Upload-Metadata header of tus: comma separated pairs of key and base64 value
+func parseUploadMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		parts := strings.Fields(pair)
+		switch len(parts) {
+		case 0:
+			continue
+		case 1:
+			metadata[parts[0]] = ""
+		case 2:
+			value, err := base64.StdEncoding.DecodeString(parts[1])
+			if err != nil {
+				return nil, err
+			}
+			metadata[parts[0]] = string(value)
+		default:
+			return nil, errors.New("wrong metadata")
+		}
+	}
+
+	return metadata, nil
+}
+
+// TusCheck rejects requests of unsupported tus protocol versions and marks responses with supported one
+func (h *Handler) TusCheck() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		gCtx.Header("Tus-Resumable", model.TusVersion)
+
+		if gCtx.GetHeader("Tus-Resumable") != model.TusVersion {
+			gCtx.Header("Tus-Version", model.TusVersion)
+			gCtx.AbortWithStatusJSON(http.StatusPreconditionFailed, gin.H{"message": "неподдерживаемая версия tus"})
+			return
+		}
+
+		gCtx.Next()
+	}
+}
+
+// getUpload loads upload of :uploadId which belongs to project from context
+func (h *Handler) getUpload(context *gin.Context) (model.Upload, bool) {
+	project, err := model.GetProject(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return model.Upload{}, false
+	}
+
+	uploadId, err := uuid.Parse(context.Param("uploadId"))
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return model.Upload{}, false
+	}
+
+	upload, err := h.redisClient.GetUpload(context.Request.Context(), uploadId)
+	if err == nil && upload.ProjectId != project.ProjectId {
+		err = model.NotFound
+	}
+	if err != nil {
+		context.AbortWithStatusJSON(repoErrorStatus(err), gin.H{"message": err.Error()})
+		return model.Upload{}, false
+	}
+
+	return upload, true
+}
+
+// removeUpload deletes upload and its chunks
+func (h *Handler) removeUpload(upload model.Upload) {
+	ctx := context.Background()
+	if err := h.redisClient.DeleteUpload(ctx, upload.UploadId); err != nil {
+		h.logger.Error(err)
+	}
+
+	for _, part := range upload.Parts {
+		if err := h.storage.Delete(ctx, part); err != nil {
+			h.logger.Error(err)
+		}
+	}
+}
+
+// CreateUpload godoc
+// @Summary      Create resumable upload
+// @Description  Creates tus upload of project media. File name is passed in Upload-Metadata as "filename <base64>".
+// @Description  Location of created upload is returned, file is sent to it with PATCH requests.
+// @Description  Project version is checked only here, media is saved over changes made during upload.
+// @Tags         Upload
+// @Produce      json
+// @Param        projectId  path  string  true  "Project Id"
+// @Param        Tus-Resumable  header  string  true  "Protocol version, 1.0.0"
+// @Param        Upload-Length  header  int  true  "Size of file"
+// @Param        Upload-Metadata  header  string  true  "Metadata of file"
+// @Param        If-Match  header  string  true  "Project version got from ETag"
+// @Success      201
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      412  {object}  error
+// @Failure      413  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/projects/{projectId}/uploads [post]
+func (h *Handler) CreateUpload(context *gin.Context) {
+	project, err := model.GetProject(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	length, err := strconv.ParseInt(context.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || length <= 0 {
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "неверный размер файла"})
+		return
+	}
+
+	if h.mediaLimits.MaxSize > 0 && length > h.mediaLimits.MaxSize {
+		context.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"message": "файл слишком большой"})
+		return
+	}
+
+	metadata, err := parseUploadMetadata(context.GetHeader("Upload-Metadata"))
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "неверный формат Upload-Metadata"})
+		return
+	}
+
+	extension := strings.ToLower(filepath.Ext(metadata["filename"]))
+	if _, ok := availableFormats[extension]; !ok {
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": wrongFormatMessage})
+		return
+	}
+
+	upload := model.Upload{
+		UploadId:  uuid.New(),
+		ProjectId: project.ProjectId,
+		UserId:    project.UserId,
+		Filename:  metadata["filename"],
+		Length:    length,
+		Created:   time.Now(),
+	}
+
+	if err = h.redisClient.CreateUpload(context.Request.Context(), upload, h.uploadConfig.Expiration); err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	context.Header("Location", fmt.Sprintf("/api/projects/%s/uploads/%s", project.ProjectId, upload.UploadId))
+	context.Status(http.StatusCreated)
+}
+
+// GetUploadOffset godoc
+// @Summary      Get offset of resumable upload
+// @Description  Returns number of received bytes in Upload-Offset header, upload is resumed from it
+// @Tags         Upload
+// @Param        projectId  path  string  true  "Project Id"
+// @Param        uploadId  path  string  true  "Upload Id"
+// @Param        Tus-Resumable  header  string  true  "Protocol version, 1.0.0"
+// @Success      200
+// @Failure      401  {object}  error
+// @Failure      404  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/projects/{projectId}/uploads/{uploadId} [head]
+func (h *Handler) GetUploadOffset(context *gin.Context) {
+	upload, ok := h.getUpload(context)
+	if !ok {
+		return
+	}
+
+	context.Header("Cache-Control", "no-store")
+	context.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	context.Header("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	context.Status(http.StatusOK)
+}
+
+// UploadChunk godoc
+// @Summary      Upload chunk of file
+// @Description  Appends request body to upload at Upload-Offset, part of body received before connection is broken
+// @Description  is kept and new offset is returned in Upload-Offset. When the last chunk is received, file is processed
+// @Description  the same way as uploaded with /media under edit lock of project and new project ETag is returned.
+// @Description  If file is not saved, chunks are kept and empty chunk at Upload-Length completes upload again.
+// @Tags         Upload
+// @Accept       octet-stream
+// @Param        projectId  path  string  true  "Project Id"
+// @Param        uploadId  path  string  true  "Upload Id"
+// @Param        Tus-Resumable  header  string  true  "Protocol version, 1.0.0"
+// @Param        Upload-Offset  header  int  true  "Offset of chunk"
+// @Success      204
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      404  {object}  error
+// @Failure      409  {object}  error
+// @Failure      412  {object}  error
+// @Failure      415  {object}  error
+// @Failure      422  {object}  error
+// @Failure      423  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/projects/{projectId}/uploads/{uploadId} [patch]
+func (h *Handler) UploadChunk(context *gin.Context) {
+	if context.ContentType() != tusContentType {
+		context.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{"message": "неверный Content-Type"})
+		return
+	}
+
+	offset, err := strconv.ParseInt(context.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "неверный Upload-Offset"})
+		return
+	}
+
+	upload, ok := h.getUpload(context)
+	if !ok {
+		return
+	}
+
+	// all chunks are received, but file was not saved, so empty chunk at the end completes upload again
+	if offset == upload.Length && upload.Offset == upload.Length {
+		h.finishUpload(context, upload)
+		return
+	}
+
+	if offset != upload.Offset {
+		context.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+		context.AbortWithStatusJSON(http.StatusConflict, gin.H{"message": model.OffsetMismatch.Error()})
+		return
+	}
+
+	if context.Request.ContentLength > upload.Length-offset {
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "размер части больше размера файла"})
+		return
+	}
+
+	// chunk is staged on disk, so bytes received before connection is broken are kept
+	// and client resumes upload after them
+	staged, err := os.CreateTemp(h.uploadConfig.TempDir, "chunk-*")
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	defer func() {
+		staged.Close()
+		os.Remove(staged.Name())
+	}()
+
+	body := &countingReader{reader: io.LimitReader(context.Request.Body, upload.Length-offset)}
+	if _, err = io.Copy(staged, body); err != nil && body.err == nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	if body.err != nil {
+		h.logger.Warnf("upload %s is interrupted after %d bytes of chunk: %s", upload.UploadId, body.size, body.err)
+	}
+
+	if body.size == 0 {
+		context.Header("Upload-Offset", strconv.FormatInt(offset, 10))
+		context.Status(http.StatusNoContent)
+		return
+	}
+
+	if _, err = staged.Seek(0, io.SeekStart); err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	chunk, newOffset, err := h.storeChunk(upload, offset, staged, body.size)
+	if err != nil {
+		status := repoErrorStatus(err)
+		if errors.Is(err, model.OffsetMismatch) {
+			status = http.StatusConflict
+		}
+		context.AbortWithStatusJSON(status, gin.H{"message": err.Error()})
+		return
+	}
+	upload.Parts = append(upload.Parts, chunk)
+
+	if body.err != nil {
+		context.Header("Upload-Offset", strconv.FormatInt(newOffset, 10))
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "передача части прервана"})
+		return
+	}
+
+	if newOffset == upload.Length {
+		h.finishUpload(context, upload)
+		return
+	}
+
+	context.Header("Upload-Offset", strconv.FormatInt(newOffset, 10))
+	context.Status(http.StatusNoContent)
+}
+
+// storeChunk puts staged chunk to storage as separate object, so any replica can receive next one,
+// and advances offset of upload. It is not bound to request, so part of chunk received before connection
+// was broken is kept too. Name of chunk and new offset are returned.
+func (h *Handler) storeChunk(upload model.Upload, offset int64, chunk io.Reader, size int64) (string, int64, error) {
+	ctx := context.Background()
+	name := upload.ChunkName(offset)
+	if err := h.storage.Put(ctx, name, chunk, size); err != nil {
+		h.storage.Delete(ctx, name)
+		return "", 0, err
+	}
+
+	newOffset, err := h.redisClient.AdvanceUpload(ctx, upload.UploadId, offset, size, name, h.uploadConfig.Expiration)
+	if err != nil {
+		h.storage.Delete(ctx, name)
+		return "", 0, err
+	}
+
+	return name, newOffset, nil
+}
+
+// finishUpload saves received file under edit lock of project and returns new project ETag
+func (h *Handler) finishUpload(context *gin.Context, upload model.Upload) {
+	release, ok := h.acquireEditLock(context)
+	if !ok {
+		return
+	}
+	defer release()
+
+	version, err := h.completeUpload(context, upload)
+	if err != nil {
+		h.logger.Error(err)
+		context.AbortWithStatusJSON(repoErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+
+	context.Header("ETag", projectETag(version))
+	context.Header("Upload-Offset", strconv.FormatInt(upload.Length, 10))
+	context.Status(http.StatusNoContent)
+}
+
+// completeUpload joins chunks into media file and runs the same processing as UploadMedia.
+// Upload is removed when file is saved or rejected, otherwise chunks are kept, so client can complete it again.
+func (h *Handler) completeUpload(context *gin.Context, upload model.Upload) (int64, error) {
+	project, err := model.GetProject(context)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx := context.Request.Context()
+	filename := uuid.New()
+	extension := strings.ToLower(filepath.Ext(upload.Filename))
+	name := filename.String() + extension
+	if err = storage.Concat(ctx, h.storage, upload.Parts, name, upload.Length); err != nil {
+		return 0, err
+	}
+
+	version, err := h.processMedia(ctx, project, filename, extension, model.GetLockToken(context))
+	if err != nil && !errors.Is(err, model.InvalidMedia) {
+		// file is joined again on next attempt
+		if err := h.storage.Delete(ctx, name); err != nil {
+			h.logger.Error(err)
+		}
+		return 0, err
+	}
+
+	h.removeUpload(upload)
+	return version, err
+}
+
+// DeleteUpload godoc
+// @Summary      Cancel resumable upload
+// @Description  Deletes upload and received chunks
+// @Tags         Upload
+// @Param        projectId  path  string  true  "Project Id"
+// @Param        uploadId  path  string  true  "Upload Id"
+// @Param        Tus-Resumable  header  string  true  "Protocol version, 1.0.0"
+// @Success      204
+// @Failure      401  {object}  error
+// @Failure      404  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/projects/{projectId}/uploads/{uploadId} [delete]
+func (h *Handler) DeleteUpload(context *gin.Context) {
+	upload, ok := h.getUpload(context)
+	if !ok {
+		return
+	}
+
+	h.removeUpload(upload)
+	context.Status(http.StatusNoContent)
+}
+
+// countingReader counts bytes read from reader and keeps its read error, e.g. broken connection of client
+type countingReader struct {
+	reader io.Reader
+	size   int64
+	err    error
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.size += int64(n)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"tiflo/model"
+	"tiflo/pkg/storage"
+	"tiflo/pkg/waveform"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// getWaveform returns waveform of media at zoom from cache, on cache miss waveforms of all zooms are computed and cached
+func (h *Handler) getWaveform(ctx context.Context, name string, zoom int) (waveform.Waveform, error) {
+	reader, err := h.storage.Get(ctx, waveform.CacheName(name, zoom))
+	if err == nil {
+		defer reader.Close()
+		return waveform.Read(reader)
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return waveform.Waveform{}, err
+	}
+
+	waveforms, err := h.mediaService.Waveform(ctx, name, h.waveformZooms)
+	if err != nil {
+		return waveform.Waveform{}, err
+	}
+
+	var result waveform.Waveform
+	for _, w := range waveforms {
+		if w.SamplesPerPixel == zoom {
+			result = w
+		}
+
+		// media files are never changed, so cache is never invalidated
+		var buf bytes.Buffer
+		if _, err = w.WriteTo(&buf); err == nil {
+			err = h.storage.Put(ctx, waveform.CacheName(name, w.SamplesPerPixel), &buf, int64(buf.Len()))
+		}
+		if err != nil {
+			h.logger.Error("cache waveform: ", err)
+		}
+	}
+
+	return result, nil
+}
+
+// GetWaveform godoc
+// @Summary      Get waveform
+// @Description  Min/max peaks of project audio or of audio part in audiowaveform format, JSON or binary (.dat)
+// @Tags         Audio
+// @Produce      json
+// @Produce      octet-stream
+// @Param        projectId  path  string  true  "Project Id"
+// @Param        partId  query  string  false  "Audio part Id, audio of project by default"
+// @Param        zoom  query  int  false  "Samples per pixel, the smallest configured zoom by default"
+// @Param        format  query  string  false  "json (default) or dat"
+// @Success      200  {object}  waveform.Waveform
+// @Failure      400  {object}  error
+// @Failure      401  {object}  error
+// @Failure      404  {object}  error
+// @Failure      500  {object}  error
+// @Router       /api/projects/{projectId}/waveform [get]
+func (h *Handler) GetWaveform(context *gin.Context) {
+	project, err := model.GetProject(context)
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	name := project.AudioPath
+	if partIdString := context.Query("partId"); partIdString != "" {
+		partId, err := uuid.Parse(partIdString)
+		if err != nil {
+			context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "неверный id части"})
+			return
+		}
+
+		name = ""
+		for _, part := range project.AudioParts {
+			if part.PartId == partId {
+				name = part.Path
+			}
+		}
+	}
+	if name == "" {
+		context.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "аудио не найдено"})
+		return
+	}
+
+	zoom := h.waveformZooms[0]
+	if zoomString := context.Query("zoom"); zoomString != "" {
+		zoom, err = strconv.Atoi(zoomString)
+		if err != nil || !containsZoom(h.waveformZooms, zoom) {
+			context.AbortWithStatusJSON(http.StatusBadRequest,
+				gin.H{"message": fmt.Sprintf("неверный масштаб, доступны: %v", h.waveformZooms)})
+			return
+		}
+	}
+
+	format := context.DefaultQuery("format", "json")
+	if format != "json" && format != "dat" {
+		context.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "неверный формат, доступны: json, dat"})
+		return
+	}
+
+	w, err := h.getWaveform(context.Request.Context(), name, zoom)
+	if err != nil {
+		h.logger.Error(err)
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	// waveform of media file never changes
+	context.Header("Cache-Control", "private, max-age=86400")
+	if format == "json" {
+		context.JSON(http.StatusOK, w)
+		return
+	}
+
+	var buf bytes.Buffer
+	if _, err = w.WriteTo(&buf); err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	context.Data(http.StatusOK, "application/octet-stream", buf.Bytes())
+}
+
+func containsZoom(zooms []int, zoom int) bool {
+	for _, z := range zooms {
+		if z == zoom {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"tiflo/model"
+
+	"github.com/google/uuid"
+)
+
+const createLexiconEntryQuery = `INSERT INTO lexicon_entry(user_id, project_id, term, alias, phoneme, alphabet)
+	VALUES ($1, $2, $3, $4, $5, $6) RETURNING entry_id, created;`
+
+// scanCreatedLexiconEntry reads id of inserted entry, duplicate term gives model.Conflict
+func scanCreatedLexiconEntry(row pgx.Row, entry *model.LexiconEntry) error {
+	if err := row.Scan(&entry.EntryId, &entry.Created); err != nil {
+		if pqError, ok := err.(*pgconn.PgError); ok {
+			if pqError.Code == "23505" {
+				return model.Conflict
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *RepositoryPostgres) CreateLexiconEntry(context context.Context, entry model.LexiconEntry) (model.LexiconEntry, error) {
+	row := r.db.QueryRow(context, createLexiconEntryQuery, entry.UserId, entry.ProjectId, entry.Term, entry.Alias,
+		entry.Phoneme, entry.Alphabet)
+	if err := scanCreatedLexiconEntry(row, &entry); err != nil {
+		r.logger.Error(err)
+		return model.LexiconEntry{}, err
+	}
+
+	return entry, nil
+}
+
+// CreateProjectLexiconEntry adds entry to lexicon of project. Entry changes what re-voicing of project produces,
+// so it is added only to project of expected version and new version is returned.
+func (r *RepositoryPostgres) CreateProjectLexiconEntry(context context.Context, project model.Project,
+	entry model.LexiconEntry) (model.LexiconEntry, int64, error) {
+	entry.UserId, entry.ProjectId = project.UserId, &project.ProjectId
+	version, err := r.inVersionedTx(context, project.ProjectId, project.UserId, project.Version, func(tx pgx.Tx) error {
+		row := tx.QueryRow(context, createLexiconEntryQuery, entry.UserId, entry.ProjectId, entry.Term, entry.Alias,
+			entry.Phoneme, entry.Alphabet)
+		return scanCreatedLexiconEntry(row, &entry)
+	})
+	if err != nil {
+		return model.LexiconEntry{}, 0, err
+	}
+
+	return entry, version, nil
+}
+
+// GetLexicon returns user's entries, and if projectId is set, entries of this project too
+func (r *RepositoryPostgres) GetLexicon(context context.Context, userId uuid.UUID, projectId *uuid.UUID) ([]model.LexiconEntry, error) {
+	query := `
+	SELECT entry_id, user_id, project_id, term, alias, phoneme, alphabet, created
+	FROM lexicon_entry
+	WHERE user_id = $1 AND (project_id IS NULL OR project_id = $2)
+	ORDER BY created
+	`
+
+	rows, err := r.db.Query(context, query, userId, projectId)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]model.LexiconEntry, 0)
+	for rows.Next() {
+		var entry model.LexiconEntry
+		if err = rows.Scan(&entry.EntryId, &entry.UserId, &entry.ProjectId, &entry.Term, &entry.Alias, &entry.Phoneme,
+			&entry.Alphabet, &entry.Created); err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+const deleteLexiconEntryQuery = `DELETE FROM lexicon_entry
+	WHERE entry_id=$1 AND user_id=$2 AND project_id IS NOT DISTINCT FROM $3 RETURNING entry_id;`
+
+// scanDeletedLexiconEntry checks that entry was deleted, missing entry gives model.NotFound
+func scanDeletedLexiconEntry(row pgx.Row) error {
+	var entryId uuid.UUID
+	if err := row.Scan(&entryId); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.NotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// DeleteLexiconEntry deletes user's entry, project entry is deleted only if entry.ProjectId matches
+func (r *RepositoryPostgres) DeleteLexiconEntry(context context.Context, entry model.LexiconEntry) error {
+	row := r.db.QueryRow(context, deleteLexiconEntryQuery, entry.EntryId, entry.UserId, entry.ProjectId)
+	if err := scanDeletedLexiconEntry(row); err != nil {
+		if !errors.Is(err, model.NotFound) {
+			r.logger.Error(err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// DeleteProjectLexiconEntry deletes entry of project lexicon if project still has expected version,
+// new version is returned
+func (r *RepositoryPostgres) DeleteProjectLexiconEntry(context context.Context, project model.Project,
+	entryId uuid.UUID) (int64, error) {
+	return r.inVersionedTx(context, project.ProjectId, project.UserId, project.Version, func(tx pgx.Tx) error {
+		row := tx.QueryRow(context, deleteLexiconEntryQuery, entryId, project.UserId, project.ProjectId)
+		return scanDeletedLexiconEntry(row)
+	})
+}
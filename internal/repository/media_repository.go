@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+
+	"tiflo/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// GetReferencedMedia returns names of all media files used by projects, including projects in trash, and audio parts
+func (r *RepositoryPostgres) GetReferencedMedia(context context.Context) (map[string]bool, error) {
+	query := `
+	SELECT video_path FROM project
+	UNION SELECT audio_path FROM project
+	UNION SELECT image_path FROM project
+	UNION SELECT proxy_path FROM project
+	UNION SELECT hls_path FROM project
+	UNION SELECT output_path FROM project
+	UNION SELECT output_hls_path FROM project
+	UNION SELECT thumbnails_path FROM project
+	UNION SELECT path FROM audio_part
+	`
+
+	rows, err := r.db.Query(context, query)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	referenced := make(map[string]bool)
+	for rows.Next() {
+		var name *string
+		if err = rows.Scan(&name); err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+
+		if name != nil && *name != "" {
+			referenced[*name] = true
+		}
+	}
+
+	return referenced, rows.Err()
+}
+
+func (r *RepositoryPostgres) SetMediaPreview(context context.Context, project model.Project) error {
+	query := `UPDATE "project" SET proxy_path=$1, hls_path=$2, thumbnails_path=$3 WHERE project_id=$4 AND video_path=$5;`
+	tag, err := r.db.Exec(context, query, project.ProxyPath, project.HlsPath, project.ThumbnailsPath, project.ProjectId,
+		project.VideoPath)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return model.NotFound
+	}
+
+	return nil
+}
+
+func (r *RepositoryPostgres) SetOutput(context context.Context, project model.Project) (int64, error) {
+	return r.inVersionedTx(context, project.ProjectId, project.UserId, project.Version, func(tx pgx.Tx) error {
+		query := `UPDATE "project" SET output_path=$1 WHERE project_id=$2 AND video_path=$3;`
+		tag, err := tx.Exec(context, query, project.OutputPath, project.ProjectId, project.VideoPath)
+		if err != nil {
+			return err
+		}
+
+		if tag.RowsAffected() == 0 {
+			return model.NotFound
+		}
+
+		return nil
+	})
+}
+
+func (r *RepositoryPostgres) SetOutputPreview(context context.Context, project model.Project) error {
+	query := `UPDATE "project" SET output_hls_path=$1 WHERE project_id=$2 AND video_path=$3;`
+	tag, err := r.db.Exec(context, query, project.OutputHlsPath, project.ProjectId, project.VideoPath)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return model.NotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"tiflo/model"
+
+	"github.com/google/uuid"
+)
+
+func (r *RepositoryPostgres) CreateNote(context context.Context, project model.Project, note model.Note) (model.Note, int64, error) {
+	version, err := r.inVersionedTx(context, project.ProjectId, project.UserId, project.Version, func(tx pgx.Tx) error {
+		query := `INSERT INTO note(project_id, parent_id, part_id, time, user_id, text) VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING note_id, created;`
+		row := tx.QueryRow(context, query, note.ProjectId, note.ParentId, note.PartId, note.Time, note.UserId, note.Text)
+		if err := row.Scan(&note.NoteId, &note.Created); err != nil {
+			return err
+		}
+
+		for _, userId := range note.Mentions {
+			query = `INSERT INTO note_mention(note_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING;`
+			if _, err := tx.Exec(context, query, note.NoteId, userId); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return model.Note{}, 0, err
+	}
+
+	return note, version, nil
+}
+
+func (r *RepositoryPostgres) GetNote(context context.Context, projectId uuid.UUID, noteId uuid.UUID) (model.Note, error) {
+	query := `SELECT note_id, project_id, parent_id, part_id, time, user_id FROM note WHERE note_id=$1 AND project_id=$2;`
+
+	var note model.Note
+	row := r.db.QueryRow(context, query, noteId, projectId)
+	if err := row.Scan(&note.NoteId, &note.ProjectId, &note.ParentId, &note.PartId, &note.Time, &note.UserId); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Note{}, model.NotFound
+		}
+		r.logger.Error(err)
+		return model.Note{}, err
+	}
+
+	return note, nil
+}
+
+// GetNotes returns all notes of project sorted by creation time
+func (r *RepositoryPostgres) GetNotes(context context.Context, projectId uuid.UUID) ([]model.Note, error) {
+	query := `
+	SELECT 
+		n.note_id,
+		n.parent_id,
+		n.part_id,
+		n.time,
+		n.user_id,
+		n.text,
+		n.created,
+		n.resolved,
+		n.resolved_by,
+		n.resolved_at,
+		COALESCE(array_agg(m.user_id::text) FILTER (WHERE m.user_id IS NOT NULL), '{}')
+	FROM 
+		note n
+	LEFT JOIN 
+		note_mention m ON n.note_id = m.note_id
+	WHERE 
+		n.project_id = $1
+	GROUP BY n.note_id
+	ORDER BY n.created
+	`
+
+	rows, err := r.db.Query(context, query, projectId)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := make([]model.Note, 0)
+	for rows.Next() {
+		var note model.Note
+		var mentions []string
+
+		if err = rows.Scan(&note.NoteId, &note.ParentId, &note.PartId, &note.Time, &note.UserId, &note.Text,
+			&note.Created, &note.Resolved, &note.ResolvedBy, &note.ResolvedAt, &mentions); err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+
+		note.ProjectId = projectId
+		note.Mentions = make([]uuid.UUID, 0, len(mentions))
+		for _, mention := range mentions {
+			userId, err := uuid.Parse(mention)
+			if err != nil {
+				return nil, err
+			}
+			note.Mentions = append(note.Mentions, userId)
+		}
+
+		notes = append(notes, note)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return notes, nil
+}
+
+// ResolveNote marks thread as resolved by user or opens it again
+func (r *RepositoryPostgres) ResolveNote(context context.Context, project model.Project, note model.Note) (int64, error) {
+	return r.inVersionedTx(context, project.ProjectId, project.UserId, project.Version, func(tx pgx.Tx) error {
+		query := `UPDATE note SET resolved=$1, resolved_by=$2, resolved_at=$3 WHERE note_id=$4 AND project_id=$5
+			RETURNING note_id;`
+
+		var noteId uuid.UUID
+		row := tx.QueryRow(context, query, note.Resolved, note.ResolvedBy, note.ResolvedAt, note.NoteId, note.ProjectId)
+		if err := row.Scan(&noteId); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return model.NotFound
+			}
+			return err
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"tiflo/model"
+
+	"github.com/google/uuid"
+)
+
+func (r *RepositoryPostgres) SetProjectTemplate(context context.Context, project model.Project) (int64, error) {
+	return r.inVersionedTx(context, project.ProjectId, project.UserId, project.Version, func(tx pgx.Tx) error {
+		query := `UPDATE "project" SET is_template=$1 WHERE project_id=$2;`
+		_, err := tx.Exec(context, query, project.IsTemplate, project.ProjectId)
+		return err
+	})
+}
+
+// copyProjectLexicon copies lexicon entries of one project to another one
+func copyProjectLexicon(context context.Context, tx pgx.Tx, fromId, toId uuid.UUID) error {
+	query := `
+	INSERT INTO lexicon_entry(user_id, project_id, term, alias, phoneme, alphabet)
+	SELECT user_id, $2, term, alias, phoneme, alphabet
+	FROM lexicon_entry
+	WHERE project_id = $1
+	`
+
+	_, err := tx.Exec(context, query, fromId, toId)
+	return err
+}
+
+// CreateProjectFromTemplate creates empty project which inherits voice, lint rules and lexicon of user's template
+func (r *RepositoryPostgres) CreateProjectFromTemplate(context context.Context, userId, templateId uuid.UUID) (model.Project, error) {
+	tx, err := r.db.Begin(context)
+	if err != nil {
+		r.logger.Error(err)
+		return model.Project{}, err
+	}
+	defer tx.Rollback(context)
+
+	query := `
+	INSERT INTO "project"(user_id, ssml, voice, lint_config)
+	SELECT user_id, ssml, voice, lint_config
+	FROM project
+	WHERE project_id = $1 AND user_id = $2 AND is_template AND deleted_at IS NULL
+	RETURNING project_id, name, user_id, created, updated, version, ssml, voice;
+	`
+
+	var newProject model.Project
+	row := tx.QueryRow(context, query, templateId, userId)
+	if err = row.Scan(&newProject.ProjectId, &newProject.Name, &newProject.UserId, &newProject.Created,
+		&newProject.Updated, &newProject.Version, &newProject.SSML, &newProject.Voice); err != nil {
+		r.logger.Error(err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Project{}, model.NotFound
+		}
+		return model.Project{}, err
+	}
+
+	if err = copyProjectLexicon(context, tx, templateId, newProject.ProjectId); err != nil {
+		r.logger.Error(err)
+		return model.Project{}, err
+	}
+
+	if err = tx.Commit(context); err != nil {
+		r.logger.Error(err)
+		return model.Project{}, err
+	}
+
+	return newProject, nil
+}
+
+// insertProject saves project with its audio parts, ids of project and parts have to be already generated
+func insertProject(context context.Context, tx pgx.Tx, project model.Project) (model.Project, error) {
+	query := `
+	INSERT INTO "project"(project_id, user_id, name, media_type, video_path, audio_path, image_path, lint_config, ssml, voice,
+		media_probe, proxy_path, hls_path, output_hls_path, thumbnails_path, output_path)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+	RETURNING name, created, updated, version;
+	`
+
+	row := tx.QueryRow(context, query, project.ProjectId, project.UserId, project.Name, project.MediaType,
+		project.VideoPath, project.AudioPath, project.ImagePath, project.LintConfig, project.SSML, project.Voice,
+		project.Probe, project.ProxyPath, project.HlsPath, project.OutputHlsPath,
+		project.ThumbnailsPath, project.OutputPath)
+	if err := row.Scan(&project.Name, &project.Created, &project.Updated, &project.Version); err != nil {
+		return model.Project{}, err
+	}
+
+	query = `INSERT INTO "audio_part" (part_id, project_id, start, duration, text, path, voice_input, voice, status, 
+		reviewer_id, reviewed)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);`
+	for _, part := range project.AudioParts {
+		if _, err := tx.Exec(context, query, part.PartId, project.ProjectId, part.Start, part.Duration, part.Text,
+			part.Path, part.VoiceInput, part.Voice, part.Status, part.ReviewerId, part.Reviewed); err != nil {
+			return model.Project{}, err
+		}
+	}
+
+	return project, nil
+}
+
+// DuplicateProject saves project with its audio parts as a new project and copies lexicon and transcript
+// of source project.
+// Project and part ids of duplicate have to be already generated.
+func (r *RepositoryPostgres) DuplicateProject(context context.Context, sourceId uuid.UUID, duplicate model.Project) (model.Project, error) {
+	tx, err := r.db.Begin(context)
+	if err != nil {
+		r.logger.Error(err)
+		return model.Project{}, err
+	}
+	defer tx.Rollback(context)
+
+	if duplicate, err = insertProject(context, tx, duplicate); err != nil {
+		r.logger.Error(err)
+		return model.Project{}, err
+	}
+
+	if err = copyProjectLexicon(context, tx, sourceId, duplicate.ProjectId); err != nil {
+		r.logger.Error(err)
+		return model.Project{}, err
+	}
+
+	if err = copyTranscript(context, tx, sourceId, duplicate.ProjectId); err != nil {
+		r.logger.Error(err)
+		return model.Project{}, err
+	}
+
+	if err = tx.Commit(context); err != nil {
+		r.logger.Error(err)
+		return model.Project{}, err
+	}
+
+	return duplicate, nil
+}
+
+// ImportProject saves project read from archive together with its lexicon
+func (r *RepositoryPostgres) ImportProject(context context.Context, project model.Project, lexicon []model.LexiconEntry) (model.Project, error) {
+	tx, err := r.db.Begin(context)
+	if err != nil {
+		r.logger.Error(err)
+		return model.Project{}, err
+	}
+	defer tx.Rollback(context)
+
+	if project, err = insertProject(context, tx, project); err != nil {
+		r.logger.Error(err)
+		return model.Project{}, err
+	}
+
+	query := `INSERT INTO lexicon_entry(user_id, project_id, term, alias, phoneme, alphabet) VALUES ($1, $2, $3, $4, $5, $6);`
+	for _, entry := range lexicon {
+		if _, err = tx.Exec(context, query, project.UserId, project.ProjectId, entry.Term, entry.Alias, entry.Phoneme,
+			entry.Alphabet); err != nil {
+			r.logger.Error(err)
+			return model.Project{}, err
+		}
+	}
+
+	if err = tx.Commit(context); err != nil {
+		r.logger.Error(err)
+		return model.Project{}, err
+	}
+
+	return project, nil
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"tiflo/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// projectStatusExpr derives project status from its media and audio parts, see model.ProjectStatus*
+const projectStatusExpr = `
+	CASE
+		WHEN p.video_path IS NULL OR p.video_path = '' THEN 'new'
+		WHEN NOT EXISTS (SELECT 1 FROM audio_part ap WHERE ap.project_id = p.project_id AND ap.text <> '') THEN 'draft'
+		WHEN EXISTS (SELECT 1 FROM audio_part ap WHERE ap.project_id = p.project_id AND ap.text <> ''
+			AND ap.status <> 'approved') THEN 'in_review'
+		ELSE 'ready'
+	END`
+
+var projectSortColumns = map[string]string{
+	model.ProjectSortCreated: "created",
+	model.ProjectSortName:    "name",
+	model.ProjectSortUpdated: "updated",
+}
+
+// GetProjectsList returns one page of user's projects ordered by params.Sort and project id.
+// Audio parts are loaded by a separate query for projects of the page only, summary mode skips them.
+func (r *RepositoryPostgres) GetProjectsList(context context.Context, params model.ProjectListParams) (model.ProjectList, error) {
+	column, ok := projectSortColumns[params.Sort]
+	if !ok {
+		return model.ProjectList{}, fmt.Errorf("unknown sort field %q", params.Sort)
+	}
+
+	args := []any{params.UserId}
+	arg := func(value any) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	conditions := []string{"p.user_id = $1", "p.deleted_at IS NULL"}
+	if params.MediaType != nil {
+		conditions = append(conditions, "p.media_type = "+arg(*params.MediaType))
+	}
+	if params.Template != nil {
+		conditions = append(conditions, "p.is_template = "+arg(*params.Template))
+	}
+	if params.Search != "" {
+		conditions = append(conditions, "p.name ILIKE '%' || "+arg(escapeLike(params.Search))+" || '%'")
+	}
+
+	order, compare := "ASC", ">"
+	if params.Desc {
+		order, compare = "DESC", "<"
+	}
+
+	// page filters are applied in outer query, because status is computed column
+	outerConditions := []string{"TRUE"}
+	if params.Status != "" {
+		outerConditions = append(outerConditions, "status = "+arg(params.Status))
+	}
+	if params.Cursor != nil {
+		var value any = params.Cursor.Value
+		if column != "name" {
+			cursorTime, err := time.Parse(time.RFC3339Nano, params.Cursor.Value)
+			if err != nil {
+				return model.ProjectList{}, model.InvalidCursor
+			}
+			value = cursorTime
+		}
+		outerConditions = append(outerConditions,
+			fmt.Sprintf("(%s, project_id) %s (%s, %s)", column, compare, arg(value), arg(params.Cursor.ProjectId)))
+	}
+
+	query := fmt.Sprintf(`
+	SELECT project_id, created, updated, name, media_type, video_path, image_path, user_id, version, ssml, voice, is_template, status
+	FROM (
+		SELECT
+			p.project_id,
+			COALESCE(p.created, 'epoch') AS created,
+			COALESCE(p.updated, 'epoch') AS updated,
+			COALESCE(p.name, '') AS name,
+			p.media_type,
+			COALESCE(p.video_path, '') AS video_path,
+			COALESCE(p.image_path, '') AS image_path,
+			p.user_id,
+			p.version,
+			p.ssml,
+			p.voice,
+			p.is_template,
+			%s AS status
+		FROM project p
+		WHERE %s
+	) p
+	WHERE %s
+	ORDER BY %s %s, project_id %s
+	LIMIT %s
+	`, projectStatusExpr, strings.Join(conditions, " AND "), strings.Join(outerConditions, " AND "),
+		column, order, order, arg(params.Limit+1))
+
+	rows, err := r.db.Query(context, query, args...)
+	if err != nil {
+		r.logger.Error(err)
+		return model.ProjectList{}, err
+	}
+	defer rows.Close()
+
+	list := model.ProjectList{Projects: make([]model.Project, 0, params.Limit)}
+	for rows.Next() {
+		var project model.Project
+		err = rows.Scan(&project.ProjectId, &project.Created, &project.Updated, &project.Name, &project.MediaType,
+			&project.VideoPath, &project.ImagePath, &project.UserId, &project.Version, &project.SSML, &project.Voice,
+			&project.IsTemplate, &project.Status)
+		if err != nil {
+			r.logger.Error(err)
+			return model.ProjectList{}, err
+		}
+
+		list.Projects = append(list.Projects, project)
+	}
+
+	if err = rows.Err(); err != nil {
+		return model.ProjectList{}, err
+	}
+
+	// one extra row is requested to know whether there is next page
+	if len(list.Projects) > params.Limit {
+		list.Projects = list.Projects[:params.Limit]
+		last := list.Projects[len(list.Projects)-1]
+
+		cursor := model.ProjectCursor{Sort: params.Sort, Desc: params.Desc, ProjectId: last.ProjectId}
+		switch params.Sort {
+		case model.ProjectSortName:
+			cursor.Value = last.Name
+		case model.ProjectSortUpdated:
+			cursor.Value = last.Updated.Format(time.RFC3339Nano)
+		default:
+			cursor.Value = last.Created.Format(time.RFC3339Nano)
+		}
+		list.NextCursor = cursor.Encode()
+	}
+
+	if params.Summary || len(list.Projects) == 0 {
+		return list, nil
+	}
+
+	if err = r.fillAudioParts(context, list.Projects); err != nil {
+		return model.ProjectList{}, err
+	}
+
+	return list, nil
+}
+
+// fillAudioParts loads audio parts of given projects ordered by start
+func (r *RepositoryPostgres) fillAudioParts(context context.Context, projects []model.Project) error {
+	ids := make([]uuid.UUID, 0, len(projects))
+	index := make(map[uuid.UUID]int, len(projects))
+	for i := range projects {
+		ids = append(ids, projects[i].ProjectId)
+		index[projects[i].ProjectId] = i
+		projects[i].AudioParts = []model.AudioPart{}
+	}
+
+	query := `
+	SELECT part_id, project_id, COALESCE(start, 0), COALESCE(duration, 0), COALESCE(text, ''), COALESCE(path, ''),
+		status, reviewer_id, reviewed
+	FROM audio_part
+	WHERE project_id = ANY($1)
+	ORDER BY project_id, start
+	`
+
+	rows, err := r.db.Query(context, query, ids)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var part model.AudioPart
+		if err = rows.Scan(&part.PartId, &part.ProjectId, &part.Start, &part.Duration, &part.Text, &part.Path,
+			&part.Status, &part.ReviewerId, &part.Reviewed); err != nil {
+			r.logger.Error(err)
+			return err
+		}
+
+		i := index[part.ProjectId]
+		projects[i].AudioParts = append(projects[i].AudioParts, part)
+	}
+
+	return rows.Err()
+}
+
+// escapeLike escapes wildcard characters of LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
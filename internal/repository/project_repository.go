@@ -5,17 +5,19 @@ import (
 	"database/sql"
 	"errors"
 	"github.com/jackc/pgx/v5"
+	"math"
 	"tiflo/model"
 
 	"github.com/google/uuid"
 )
 
 func (r *RepositoryPostgres) CreateProject(context context.Context, userId uuid.UUID) (model.Project, error) {
-	query := `INSERT INTO "project"(user_id) VALUES ($1) RETURNING project_id, name, user_id, created;`
+	query := `INSERT INTO "project"(user_id) VALUES ($1) RETURNING project_id, name, user_id, created, updated, version;`
 	var newProject model.Project
 
 	row := r.db.QueryRow(context, query, userId)
-	if err := row.Scan(&newProject.ProjectId, &newProject.Name, &newProject.UserId, &newProject.Created); err != nil {
+	if err := row.Scan(&newProject.ProjectId, &newProject.Name, &newProject.UserId, &newProject.Created,
+		&newProject.Updated, &newProject.Version); err != nil {
 		r.logger.Error(err)
 		return model.Project{}, err
 	}
@@ -23,107 +25,253 @@ func (r *RepositoryPostgres) CreateProject(context context.Context, userId uuid.
 	return newProject, nil
 }
 
-func (r *RepositoryPostgres) UpdateAudioPart(context context.Context, audioPart model.AudioPart) error {
-	var partId uuid.UUID
-	query := `INSERT INTO "audio_part" (part_id, project_id, start, duration, text, path)
-			VALUES
-    		($1, $2, $3, $4, $5, $6)
-			ON CONFLICT (part_id) DO UPDATE
-			SET start = EXCLUDED.start, 
-			    duration = EXCLUDED.duration, 
-			    text = EXCLUDED.text,
-			    path = EXCLUDED.path
-			    RETURNING part_id;
-	`
+// inVersionedTx locks user's project row, checks that project still has expected version, runs fn
+// in the same transaction and increments project version. New version is returned.
+func (r *RepositoryPostgres) inVersionedTx(context context.Context, projectId, userId uuid.UUID, version int64,
+	fn func(tx pgx.Tx) error) (int64, error) {
+	tx, err := r.db.Begin(context)
+	if err != nil {
+		r.logger.Error(err)
+		return 0, err
+	}
+	defer tx.Rollback(context)
 
-	row := r.db.QueryRow(context, query, audioPart.PartId, audioPart.ProjectId, audioPart.Start,
-		audioPart.Duration, audioPart.Text, audioPart.Path)
-	if err := row.Scan(&partId); err != nil {
+	var currentVersion int64
+	query := `SELECT version FROM "project" WHERE project_id=$1 AND user_id=$2 AND deleted_at IS NULL FOR UPDATE;`
+	row := tx.QueryRow(context, query, projectId, userId)
+	if err = row.Scan(&currentVersion); err != nil {
 		r.logger.Error(err)
-		return err
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, model.NotFound
+		}
+		return 0, err
 	}
 
-	return nil
-}
+	if currentVersion != version {
+		return 0, model.VersionMismatch
+	}
 
-func (r *RepositoryPostgres) RenameProject(context context.Context, project model.Project) error {
-	query := `UPDATE "project" SET name=$1 WHERE project_id=$2 AND user_id=$3 RETURNING project_id, name, user_id;`
-	var newProject model.Project
+	if err = fn(tx); err != nil {
+		r.logger.Error(err)
+		return 0, err
+	}
 
-	row := r.db.QueryRow(context, query, project.Name, project.ProjectId, project.UserId)
-	if err := row.Scan(&newProject.ProjectId, &newProject.Name, &newProject.UserId); err != nil {
+	var newVersion int64
+	query = `UPDATE "project" SET version = version + 1, updated = now() WHERE project_id=$1 RETURNING version;`
+	row = tx.QueryRow(context, query, projectId)
+	if err = row.Scan(&newVersion); err != nil {
 		r.logger.Error(err)
-		return err
+		return 0, err
 	}
 
-	return nil
+	if err = tx.Commit(context); err != nil {
+		r.logger.Error(err)
+		return 0, err
+	}
+
+	return newVersion, nil
 }
 
-func (r *RepositoryPostgres) ChangeCommentText(context context.Context, project model.Project) error {
-	query := `UPDATE audio_part SET text=$1 WHERE project_id=$2 AND part_id=$3 RETURNING part_id;`
-	var newProject model.Project
+// checkFencingToken rejects writes made under expired edit lock once newer lock holder has written,
+// zero token means that write is made without the lock
+func checkFencingToken(context context.Context, tx pgx.Tx, projectId uuid.UUID, fencingToken int64) error {
+	if fencingToken == 0 {
+		return nil
+	}
 
-	row := r.db.QueryRow(context, query, project.Name, project.ProjectId, project.UserId)
-	if err := row.Scan(&newProject.ProjectId, &newProject.Name, &newProject.UserId); err != nil {
-		r.logger.Error(err)
+	query := `UPDATE "project" SET fencing_token=$1 WHERE project_id=$2 AND fencing_token <= $1 RETURNING project_id;`
+	row := tx.QueryRow(context, query, fencingToken, projectId)
+	if err := row.Scan(&projectId); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.LockLost
+		}
 		return err
 	}
 
 	return nil
 }
 
-func (r *RepositoryPostgres) UploadMedia(context context.Context, project model.Project) error {
-	query := `UPDATE "project" SET video_path=$1, audio_path=$2, image_path=$3 WHERE user_id=$4 AND project_id=$5 RETURNING video_path;`
-
-	var path string
-	row := r.db.QueryRow(context, query, project.VideoPath, project.AudioPath, project.ImagePath, project.UserId, project.ProjectId)
-	if err := row.Scan(&path); err != nil {
-		r.logger.Error(err)
+func (r *RepositoryPostgres) RenameProject(context context.Context, project model.Project) (int64, error) {
+	return r.inVersionedTx(context, project.ProjectId, project.UserId, project.Version, func(tx pgx.Tx) error {
+		query := `UPDATE "project" SET name=$1 WHERE project_id=$2;`
+		_, err := tx.Exec(context, query, project.Name, project.ProjectId)
 		return err
-	}
+	})
+}
 
-	if len(project.AudioParts) > 0 {
-		var projectId uuid.UUID
-		query2 := `INSERT INTO "audio_part"(part_id, project_id, path, duration, start) VALUES ($1, $2, $3, $4, 0) RETURNING project_id;`
-		row = r.db.QueryRow(context, query2, project.AudioParts[0].PartId, project.AudioParts[0].ProjectId,
-			project.AudioParts[0].Path, project.AudioParts[0].Duration)
-		if err := row.Scan(&projectId); err != nil {
-			r.logger.Error(err)
+func (r *RepositoryPostgres) UploadMedia(context context.Context, project model.Project, fencingToken int64) (int64, error) {
+	return r.inVersionedTx(context, project.ProjectId, project.UserId, project.Version, func(tx pgx.Tx) error {
+		if err := checkFencingToken(context, tx, project.ProjectId, fencingToken); err != nil {
 			return err
 		}
-	}
 
-	return nil
+		// previews of previous media are dropped, new ones are made in background
+		query := `UPDATE "project" SET video_path=$1, audio_path=$2, image_path=$3, media_type=$4, media_probe=$5,
+			proxy_path=NULL, hls_path=NULL, output_path=NULL, output_hls_path=NULL, thumbnails_path=NULL
+			WHERE project_id=$6;`
+		if _, err := tx.Exec(context, query, project.VideoPath, project.AudioPath, project.ImagePath, project.MediaType,
+			project.Probe, project.ProjectId); err != nil {
+			return err
+		}
+
+		// transcript of previous audio does not match new one
+		query = `DELETE FROM transcript_segment WHERE project_id=$1;`
+		if _, err := tx.Exec(context, query, project.ProjectId); err != nil {
+			return err
+		}
+
+		if len(project.AudioParts) > 0 {
+			query = `INSERT INTO "audio_part"(part_id, project_id, path, duration, start) VALUES ($1, $2, $3, $4, 0);`
+			if _, err := tx.Exec(context, query, project.AudioParts[0].PartId, project.ProjectId,
+				project.AudioParts[0].Path, project.AudioParts[0].Duration); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
 
-func (r *RepositoryPostgres) SaveProjectAudio(context context.Context, project model.Project) error {
-	var projectId uuid.UUID
-	query := `DELETE FROM "audio_part" WHERE project_id=$1 RETURNING project_id;`
-	row := r.db.QueryRow(context, query, project.ProjectId)
-	_ = row.Scan(&projectId)
+// UpdateTimeline upserts and deletes audio parts of user's project in one transaction,
+// notes of deleted parts are re-anchored before they are deleted. Parts of other projects are never touched.
+func (r *RepositoryPostgres) UpdateTimeline(context context.Context, userId uuid.UUID, update model.TimelineUpdate) (int64, error) {
+	return r.inVersionedTx(context, update.ProjectId, userId, update.Version, func(tx pgx.Tx) error {
+		if err := checkFencingToken(context, tx, update.ProjectId, update.FencingToken); err != nil {
+			return err
+		}
 
-	for _, v := range project.AudioParts {
-		query = `INSERT INTO "audio_part"(part_id, project_id, start, text, path, duration) VALUES ($1, $2, $3, $4, $5, $6) RETURNING project_id;`
-		row = r.db.QueryRow(context, query, v.PartId, v.ProjectId, v.Start, v.Text, v.Path, v.Duration)
-		if err := row.Scan(&projectId); err != nil {
-			r.logger.Error(err)
+		// notes of deleted parts which are not re-anchored become timestamp notes, their time is converted
+		// to time of original video while parts are still in place
+		for _, partId := range update.Deleted {
+			since := int64(math.MaxInt64)
+			for _, move := range update.Moved {
+				if move.From == partId && move.Since < since {
+					since = move.Since
+				}
+			}
+
+			query := `UPDATE note n SET part_id = NULL, time = n.time - COALESCE((
+					SELECT SUM(GREATEST(0, LEAST(n.time, d.start + d.duration) - d.start))
+					FROM audio_part d
+					WHERE d.project_id = $1 AND d.text <> '' AND d.start < n.time
+				), 0)
+				WHERE n.project_id = $1 AND n.part_id = $2 AND n.time < $3;`
+			if _, err := tx.Exec(context, query, update.ProjectId, partId, since); err != nil {
+				return err
+			}
+		}
+
+		for _, audioPart := range update.Updated {
+			var partId uuid.UUID
+			query := `INSERT INTO "audio_part" (part_id, project_id, start, duration, text, path, status, reviewer_id, reviewed, voice_input, voice)
+				VALUES
+				($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+				ON CONFLICT (part_id) DO UPDATE
+				SET start = EXCLUDED.start, 
+				    duration = EXCLUDED.duration, 
+				    text = EXCLUDED.text,
+				    path = EXCLUDED.path,
+				    voice_input = EXCLUDED.voice_input,
+				    voice = EXCLUDED.voice,
+				    status = EXCLUDED.status,
+				    reviewer_id = EXCLUDED.reviewer_id,
+				    reviewed = EXCLUDED.reviewed
+				WHERE audio_part.project_id = EXCLUDED.project_id
+				    RETURNING part_id;
+			`
+
+			if audioPart.Status == "" {
+				audioPart.Status = model.PartStatusDraft
+			}
+
+			// notes anchored to part are moved by the same shift as part itself
+			noteQuery := `UPDATE note n SET time = n.time + ($1 - ap.start)
+				FROM audio_part ap
+				WHERE ap.part_id = $2 AND ap.project_id = $3 AND n.part_id = ap.part_id;`
+			if _, err := tx.Exec(context, noteQuery, audioPart.Start, audioPart.PartId, update.ProjectId); err != nil {
+				return err
+			}
+
+			row := tx.QueryRow(context, query, audioPart.PartId, update.ProjectId, audioPart.Start,
+				audioPart.Duration, audioPart.Text, audioPart.Path, audioPart.Status, audioPart.ReviewerId, audioPart.Reviewed,
+				audioPart.VoiceInput, audioPart.Voice)
+			if err := row.Scan(&partId); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return model.NotFound
+				}
+				return err
+			}
+		}
+
+		// parts which replace deleted ones are inserted above, so notes can be anchored to them
+		for _, move := range update.Moved {
+			query := `UPDATE note SET part_id = $1, time = LEAST(time + $2, $3)
+				WHERE part_id = $4 AND project_id = $5 AND time >= $6;`
+			if _, err := tx.Exec(context, query, move.To, move.Shift, move.Until, move.From, update.ProjectId,
+				move.Since); err != nil {
+				return err
+			}
+		}
+
+		for _, partId := range update.Deleted {
+			query := `DELETE FROM audio_part WHERE part_id = $1 AND project_id = $2;`
+			if _, err := tx.Exec(context, query, partId, update.ProjectId); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// UpdateAudioPartStatus sets review status of audio part, reviewer and time of review
+func (r *RepositoryPostgres) UpdateAudioPartStatus(context context.Context, project model.Project, part model.AudioPart,
+	fencingToken int64) (int64, error) {
+	return r.inVersionedTx(context, project.ProjectId, project.UserId, project.Version, func(tx pgx.Tx) error {
+		if err := checkFencingToken(context, tx, project.ProjectId, fencingToken); err != nil {
 			return err
 		}
-	}
 
-	return nil
+		var partId uuid.UUID
+		query := `UPDATE audio_part SET status=$1, reviewer_id=$2, reviewed=$3 WHERE part_id=$4 AND project_id=$5 RETURNING part_id;`
+		row := tx.QueryRow(context, query, part.Status, part.ReviewerId, part.Reviewed, part.PartId, project.ProjectId)
+		if err := row.Scan(&partId); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return model.NotFound
+			}
+			return err
+		}
+
+		return nil
+	})
 }
 
-func (r *RepositoryPostgres) DeleteProject(context context.Context, project model.Project) error {
-	query := `DELETE FROM project WHERE project_id=$1 AND user_id=$2;`
+// SetLintConfig sets style guide of user's project, nil config resets project to organisation style guide
+func (r *RepositoryPostgres) SetLintConfig(context context.Context, project model.Project) (int64, error) {
+	return r.inVersionedTx(context, project.ProjectId, project.UserId, project.Version, func(tx pgx.Tx) error {
+		query := `UPDATE "project" SET lint_config=$1 WHERE project_id=$2;`
+		_, err := tx.Exec(context, query, project.LintConfig, project.ProjectId)
+		return err
+	})
+}
 
-	row := r.db.QueryRow(context, query, project.ProjectId, project.UserId)
-	if err := row.Scan(); err != nil && !errors.Is(pgx.ErrNoRows, err) {
-		r.logger.Error(err)
+func (r *RepositoryPostgres) SetVoiceSettings(context context.Context, project model.Project) (int64, error) {
+	return r.inVersionedTx(context, project.ProjectId, project.UserId, project.Version, func(tx pgx.Tx) error {
+		query := `UPDATE "project" SET ssml=$1, voice=$2 WHERE project_id=$3;`
+		_, err := tx.Exec(context, query, project.SSML, project.Voice, project.ProjectId)
 		return err
-	}
+	})
+}
 
-	return nil
+// DeleteProject moves project to trash, it is purged after retention period
+func (r *RepositoryPostgres) DeleteProject(context context.Context, project model.Project) error {
+	_, err := r.inVersionedTx(context, project.ProjectId, project.UserId, project.Version, func(tx pgx.Tx) error {
+		query := `UPDATE project SET deleted_at = now() WHERE project_id=$1;`
+		_, err := tx.Exec(context, query, project.ProjectId)
+		return err
+	})
+
+	return err
 }
 
 func (r *RepositoryPostgres) GetProject(context context.Context, project model.Project) (model.Project, error) {
@@ -134,17 +282,35 @@ func (r *RepositoryPostgres) GetProject(context context.Context, project model.P
 		p.audio_path,
 		p.image_path,
 		p.created,
+		p.updated,
+		p.media_type,
+		p.version,
+		p.lint_config,
+		p.media_probe,
+		COALESCE(p.proxy_path, ''),
+		COALESCE(p.hls_path, ''),
+		COALESCE(p.output_hls_path, ''),
+		COALESCE(p.thumbnails_path, ''),
+		COALESCE(p.output_path, ''),
+		p.ssml,
+		p.voice,
+		p.is_template,
 		ap.part_id,
 		ap.start,
 		ap.duration,
 		ap.text,
-		ap.path
+		ap.path,
+		ap.voice_input,
+		ap.voice,
+		ap.status,
+		ap.reviewer_id,
+		ap.reviewed
 	FROM 
 		project p
 	LEFT JOIN 
 		audio_part ap ON p.project_id = ap.project_id
 	WHERE 
-		p.project_id = $1 AND user_id=$2
+		p.project_id = $1 AND user_id=$2 AND p.deleted_at IS NULL
 	`
 
 	rows, err := r.db.Query(context, query, project.ProjectId, project.UserId)
@@ -153,25 +319,43 @@ func (r *RepositoryPostgres) GetProject(context context.Context, project model.P
 	}
 	defer rows.Close()
 
-	var projectVideoPath, projectAudioPath sql.NullString
+	found := false
+	var projectVideoPath, projectAudioPath, projectImagePath sql.NullString
 	for rows.Next() {
 		var ap model.AudioPart
-		var audioPath, audioText sql.NullString
-		var created sql.NullTime
+		var partId *uuid.UUID
+		var audioPath, audioText, voiceInput, voice, status sql.NullString
+		var created, updated sql.NullTime
 		var duration, start sql.NullInt64
 
-		err = rows.Scan(&project.Name, &projectVideoPath, &projectAudioPath, &project.AudioPath, &created, &ap.PartId, &start, &duration, &audioText, &audioPath)
+		err = rows.Scan(&project.Name, &projectVideoPath, &projectAudioPath, &projectImagePath, &created, &updated,
+			&project.MediaType, &project.Version,
+			&project.LintConfig, &project.Probe, &project.ProxyPath, &project.HlsPath, &project.OutputHlsPath,
+			&project.ThumbnailsPath, &project.OutputPath, &project.SSML, &project.Voice, &project.IsTemplate, &partId, &start, &duration,
+			&audioText, &audioPath, &voiceInput, &voice, &status,
+			&ap.ReviewerId, &ap.Reviewed)
 		if err != nil {
 			return model.Project{}, err
 		}
+		found = true
 		project.VideoPath = projectVideoPath.String
 		project.AudioPath = projectAudioPath.String
+		project.ImagePath = projectImagePath.String
 		project.Created = created.Time
+		project.Updated = updated.Time
+
+		if partId == nil {
+			continue
+		}
+		ap.PartId = *partId
 		ap.Path = audioPath.String
 		ap.Start = start.Int64
 		ap.Duration = duration.Int64
 		ap.ProjectId = project.ProjectId
 		ap.Text = audioText.String
+		ap.VoiceInput = voiceInput.String
+		ap.Voice = voice.String
+		ap.Status = status.String
 
 		project.AudioParts = append(project.AudioParts, ap)
 	}
@@ -180,6 +364,10 @@ func (r *RepositoryPostgres) GetProject(context context.Context, project model.P
 		return model.Project{}, err
 	}
 
+	if !found {
+		return model.Project{}, model.NotFound
+	}
+
 	return project, nil
 }
 
@@ -236,27 +424,10 @@ func (r *RepositoryPostgres) GetAudioPartBySplitPoint(context context.Context, s
 	return audioPart, nil
 }
 
-func (r *RepositoryPostgres) DeleteAudioPart(context context.Context, audioPart model.AudioPart) (model.AudioPart, error) {
-	query := `
-	DELETE FROM audio_part WHERE part_id = $1 AND project_id=$2 RETURNING part_id, duration, start, text, path;
-	`
-	var path, text sql.NullString
-
-	row := r.db.QueryRow(context, query, audioPart.PartId, audioPart.ProjectId)
-	if err := row.Scan(&audioPart.PartId, &audioPart.Duration, &audioPart.Start, &text, &path); err != nil {
-		r.logger.Error(err)
-		return audioPart, err
-	}
-	audioPart.Text = text.String
-	audioPart.Path = path.String
-
-	return audioPart, nil
-}
-
 func (r *RepositoryPostgres) GetAudioPartsAfterSplitPoint(context context.Context, splitPoint int64,
 	projectId uuid.UUID) ([]model.AudioPart, error) {
 	query := `
-	SELECT part_id, project_id, start, duration, text, path
+	SELECT part_id, project_id, start, duration, text, path, voice_input, voice, status, reviewer_id, reviewed
 	FROM audio_part
 	WHERE 
 		 project_id=$1 AND start > $2;
@@ -273,7 +444,8 @@ func (r *RepositoryPostgres) GetAudioPartsAfterSplitPoint(context context.Contex
 		var audioPart model.AudioPart
 
 		if err = rows.Scan(&audioPart.PartId, &audioPart.ProjectId, &audioPart.Start, &audioPart.Duration,
-			&audioPart.Text, &audioPart.Path); err != nil {
+			&audioPart.Text, &audioPart.Path, &audioPart.VoiceInput, &audioPart.Voice, &audioPart.Status, &audioPart.ReviewerId,
+			&audioPart.Reviewed); err != nil {
 			r.logger.Error(err)
 			return nil, err
 		}
@@ -283,87 +455,3 @@ func (r *RepositoryPostgres) GetAudioPartsAfterSplitPoint(context context.Contex
 
 	return audioParts, nil
 }
-
-func (r *RepositoryPostgres) GetProjectsList(context context.Context, userId uuid.UUID) ([]model.Project, error) {
-	query := `
-	SELECT 
-		p.project_id,
-		p.created,
-		p.name,
-		p.video_path,
-		p.audio_path,
-		p.image_path,
-		p.user_id,
-		ap.part_id,
-		ap.start,
-		ap.duration,
-		ap.text,
-		ap.path
-	FROM 
-		project p
-	LEFT JOIN 
-		audio_part ap ON p.project_id = ap.project_id
-	WHERE 
-		p.user_id = $1
-	`
-	// TODO check if pgxpool support array_ag for group by constructions
-	rows, err := r.db.Query(context, query, userId)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	projects := map[uuid.UUID]model.Project{}
-	for rows.Next() {
-		var projectId uuid.UUID
-		var name string
-		var userId uuid.UUID
-		var created sql.NullTime
-		var partId uuid.UUID
-		var projectPath sql.NullString
-		var projectAudioPath sql.NullString
-		var projectImagePath sql.NullString
-
-		var audioPath, audioText sql.NullString
-		var duration, start sql.NullInt64
-
-		err = rows.Scan(&projectId, &created, &name, &projectPath, &projectAudioPath, &projectImagePath, &userId, &partId, &start, &duration, &audioText, &audioPath)
-		if err != nil {
-			return nil, err
-		}
-
-		project, exists := projects[projectId]
-		if !exists {
-			project = model.Project{
-				ProjectId:  projectId,
-				Name:       name,
-				VideoPath:  projectPath.String,
-				AudioPath:  projectAudioPath.String,
-				ImagePath:  projectImagePath.String,
-				UserId:     userId,
-				Created:    created.Time,
-				AudioParts: []model.AudioPart{},
-			}
-			projects[projectId] = project
-		}
-
-		project.AudioParts = append(project.AudioParts, model.AudioPart{
-			PartId:   partId,
-			Start:    start.Int64,
-			Duration: duration.Int64,
-			Text:     audioText.String,
-			Path:     audioPath.String,
-		})
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
-
-	var result []model.Project
-	for _, project := range projects {
-		result = append(result, project)
-	}
-
-	return result, nil
-}
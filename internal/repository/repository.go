@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"log"
+	"time"
 
 	"tiflo/model"
 
@@ -19,21 +20,60 @@ type Repository interface {
 	GetUser(context context.Context, user model.UserLogin) (model.User, error)
 
 	CreateProject(context context.Context, userId uuid.UUID) (model.Project, error)
-	RenameProject(context context.Context, project model.Project) error
-	DeleteProject(context context.Context, project model.Project) error
 	GetProject(context context.Context, project model.Project) (model.Project, error)
-	GetProjectsList(context context.Context, userId uuid.UUID) ([]model.Project, error)
-
-	UploadMedia(context context.Context, project model.Project) error
+	GetProjectsList(context context.Context, params model.ProjectListParams) (model.ProjectList, error)
 
-	SaveProjectAudio(context context.Context, project model.Project) error
+	// RenameProject, DeleteProject, UploadMedia and UpdateTimeline change project only
+	// if it belongs to project.UserId and still has project.Version, otherwise model.VersionMismatch is returned.
+	// New project version is returned on success.
+	RenameProject(context context.Context, project model.Project) (int64, error)
+	DeleteProject(context context.Context, project model.Project) error
+	GetTrash(context context.Context, userId uuid.UUID) ([]model.Project, error)
+	RestoreProject(context context.Context, userId, projectId uuid.UUID) (int64, error)
+	GetExpiredProjects(context context.Context, deletedBefore time.Time) ([]uuid.UUID, error)
+	PurgeProject(context context.Context, projectId uuid.UUID) ([]string, error)
+	GetReferencedMedia(context context.Context) (map[string]bool, error)
+
+	UploadMedia(context context.Context, project model.Project, fencingToken int64) (int64, error)
+	// SetMediaPreview and SetOutputPreview save previews made in background only if project still has
+	// project.VideoPath, otherwise model.NotFound is returned. Project version is not changed.
+	SetMediaPreview(context context.Context, project model.Project) error
+	SetOutputPreview(context context.Context, project model.Project) error
+	// SetOutput saves rendered audio of project of given version if project still has project.VideoPath,
+	// otherwise model.NotFound is returned. New project version is returned.
+	SetOutput(context context.Context, project model.Project) (int64, error)
+
+	UpdateTimeline(context context.Context, userId uuid.UUID, update model.TimelineUpdate) (int64, error)
+	UpdateAudioPartStatus(context context.Context, project model.Project, part model.AudioPart, fencingToken int64) (int64, error)
+
+	SetLintConfig(context context.Context, project model.Project) (int64, error)
+	SetVoiceSettings(context context.Context, project model.Project) (int64, error)
+	SetProjectTemplate(context context.Context, project model.Project) (int64, error)
+	CreateProjectFromTemplate(context context.Context, userId, templateId uuid.UUID) (model.Project, error)
+	DuplicateProject(context context.Context, sourceId uuid.UUID, duplicate model.Project) (model.Project, error)
+	ImportProject(context context.Context, project model.Project, lexicon []model.LexiconEntry) (model.Project, error)
 
 	GetAudioPartBySplitPoint(context context.Context, splitPoint int64, projectId uuid.UUID) (model.AudioPart, error)
 	GetAudioPartsAfterSplitPoint(context context.Context, splitPoint int64, projectId uuid.UUID) ([]model.AudioPart, error)
-
-	UpdateAudioPart(context context.Context, audioPart model.AudioPart) error
-	DeleteAudioPart(context context.Context, audioPart model.AudioPart) (model.AudioPart, error)
 	GetAudioPart(context context.Context, part model.AudioPart) (model.AudioPart, error)
+
+	// CreateNote and ResolveNote change notes of project of given version, new project version is returned
+	CreateNote(context context.Context, project model.Project, note model.Note) (model.Note, int64, error)
+	GetNote(context context.Context, projectId uuid.UUID, noteId uuid.UUID) (model.Note, error)
+	GetNotes(context context.Context, projectId uuid.UUID) ([]model.Note, error)
+	ResolveNote(context context.Context, project model.Project, note model.Note) (int64, error)
+
+	SearchAudioParts(context context.Context, params model.SearchParams) ([]model.SearchHit, error)
+
+	SetTranscript(context context.Context, project model.Project, segments []model.TranscriptSegment,
+		fencingToken int64) ([]model.TranscriptSegment, int64, error)
+	GetTranscript(context context.Context, projectId uuid.UUID) ([]model.TranscriptSegment, error)
+
+	CreateLexiconEntry(context context.Context, entry model.LexiconEntry) (model.LexiconEntry, error)
+	GetLexicon(context context.Context, userId uuid.UUID, projectId *uuid.UUID) ([]model.LexiconEntry, error)
+	DeleteLexiconEntry(context context.Context, entry model.LexiconEntry) error
+	CreateProjectLexiconEntry(context context.Context, project model.Project, entry model.LexiconEntry) (model.LexiconEntry, int64, error)
+	DeleteProjectLexiconEntry(context context.Context, project model.Project, entryId uuid.UUID) (int64, error)
 }
 
 type RepositoryPostgres struct {
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"tiflo/model"
+)
+
+const (
+	// SnippetStart and SnippetStop mark matches in snippet returned by SearchAudioParts
+	SnippetStart = "[[["
+	SnippetStop  = "]]]"
+)
+
+var searchConfigs = map[string]string{
+	model.SearchLanguageRussian: "russian",
+	model.SearchLanguageEnglish: "english",
+}
+
+// SearchAudioParts finds descriptions of user's projects matching websearch-like query, video time of hit
+// is its start without durations of earlier descriptions. Expressions of to_tsvector have to be the same as in indexes from init.sql.
+func (r *RepositoryPostgres) SearchAudioParts(context context.Context, params model.SearchParams) ([]model.SearchHit, error) {
+	configs := []string{searchConfigs[model.SearchLanguageRussian], searchConfigs[model.SearchLanguageEnglish]}
+	if config, ok := searchConfigs[params.Language]; ok {
+		configs = []string{config}
+	}
+
+	var matches, ranks, headline []string
+	for _, config := range configs {
+		vector := fmt.Sprintf("to_tsvector('%s', COALESCE(ap.text, ''))", config)
+		query := fmt.Sprintf("websearch_to_tsquery('%s', $2)", config)
+
+		matches = append(matches, vector+" @@ "+query)
+		ranks = append(ranks, fmt.Sprintf("ts_rank(%s, %s)", vector, query))
+		headline = append(headline, fmt.Sprintf("WHEN %s @@ %s THEN ts_headline('%s', COALESCE(ap.text, ''), %s, "+
+			"'StartSel=%s, StopSel=%s, MaxWords=25, MinWords=8')", vector, query, config, query, SnippetStart, SnippetStop))
+	}
+
+	query := fmt.Sprintf(`
+	SELECT
+		p.project_id,
+		COALESCE(p.name, ''),
+		ap.part_id,
+		COALESCE(ap.start, 0),
+		COALESCE(ap.start, 0) - COALESCE((
+			SELECT SUM(d.duration) FROM audio_part d
+			WHERE d.project_id = ap.project_id AND d.text <> '' AND d.start < ap.start
+		), 0) AS video_time,
+		COALESCE(ap.text, ''),
+		CASE %s ELSE '' END AS snippet,
+		GREATEST(%s) AS rank
+	FROM audio_part ap
+	JOIN project p ON p.project_id = ap.project_id
+	WHERE p.user_id = $1 AND p.deleted_at IS NULL
+		AND ($3::uuid IS NULL OR p.project_id = $3)
+		AND (%s)
+	ORDER BY rank DESC, p.project_id, ap.start
+	LIMIT $4 OFFSET $5
+	`, strings.Join(headline, " "), strings.Join(ranks, ", "), strings.Join(matches, " OR "))
+
+	rows, err := r.db.Query(context, query, params.UserId, params.Query, params.ProjectId, params.Limit, params.Offset)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	hits := make([]model.SearchHit, 0)
+	for rows.Next() {
+		var hit model.SearchHit
+		if err = rows.Scan(&hit.ProjectId, &hit.ProjectName, &hit.PartId, &hit.Start, &hit.VideoTime, &hit.Text,
+			&hit.Snippet, &hit.Rank); err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+
+		hits = append(hits, hit)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return hits, nil
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"tiflo/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// SetTranscript replaces transcript of project of given version under edit lock, new project version is returned.
+// Transcript is saved only if project still has audio project.AudioPath which was transcribed,
+// otherwise model.NotFound is returned.
+func (r *RepositoryPostgres) SetTranscript(context context.Context, project model.Project,
+	segments []model.TranscriptSegment, fencingToken int64) ([]model.TranscriptSegment, int64, error) {
+	version, err := r.inVersionedTx(context, project.ProjectId, project.UserId, project.Version, func(tx pgx.Tx) error {
+		if err := checkFencingToken(context, tx, project.ProjectId, fencingToken); err != nil {
+			return err
+		}
+
+		query := `SELECT project_id FROM project WHERE project_id=$1 AND audio_path=$2;`
+		if err := tx.QueryRow(context, query, project.ProjectId, project.AudioPath).Scan(&project.ProjectId); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return model.NotFound
+			}
+			return err
+		}
+
+		query = `DELETE FROM transcript_segment WHERE project_id=$1;`
+		if _, err := tx.Exec(context, query, project.ProjectId); err != nil {
+			return err
+		}
+
+		query = `INSERT INTO transcript_segment(project_id, start, duration, text, speaker) VALUES ($1, $2, $3, $4, $5)
+			RETURNING segment_id;`
+		for i := range segments {
+			segments[i].ProjectId = project.ProjectId
+			if err := tx.QueryRow(context, query, project.ProjectId, segments[i].Start, segments[i].Duration,
+				segments[i].Text, segments[i].Speaker).Scan(&segments[i].SegmentId); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return segments, version, nil
+}
+
+// GetTranscript returns transcript of project sorted by start
+func (r *RepositoryPostgres) GetTranscript(context context.Context, projectId uuid.UUID) ([]model.TranscriptSegment, error) {
+	query := `SELECT segment_id, project_id, start, duration, text, speaker FROM transcript_segment
+		WHERE project_id=$1 ORDER BY start, segment_id;`
+
+	rows, err := r.db.Query(context, query, projectId)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	segments := make([]model.TranscriptSegment, 0)
+	for rows.Next() {
+		var segment model.TranscriptSegment
+		if err = rows.Scan(&segment.SegmentId, &segment.ProjectId, &segment.Start, &segment.Duration, &segment.Text,
+			&segment.Speaker); err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+		segments = append(segments, segment)
+	}
+
+	return segments, rows.Err()
+}
+
+// copyTranscript copies transcript of one project to another one
+func copyTranscript(context context.Context, tx pgx.Tx, fromId, toId uuid.UUID) error {
+	query := `
+	INSERT INTO transcript_segment(project_id, start, duration, text, speaker)
+	SELECT $2, start, duration, text, speaker
+	FROM transcript_segment
+	WHERE project_id = $1
+	`
+
+	_, err := tx.Exec(context, query, fromId, toId)
+	return err
+}
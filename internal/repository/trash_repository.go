@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"tiflo/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GetTrash returns deleted user's projects without audio parts, recently deleted first
+func (r *RepositoryPostgres) GetTrash(context context.Context, userId uuid.UUID) ([]model.Project, error) {
+	query := `
+	SELECT project_id, COALESCE(created, 'epoch'), COALESCE(updated, 'epoch'), COALESCE(name, ''), media_type,
+		COALESCE(video_path, ''), COALESCE(image_path, ''), user_id, version, deleted_at
+	FROM project
+	WHERE user_id = $1 AND deleted_at IS NOT NULL
+	ORDER BY deleted_at DESC, project_id
+	`
+
+	rows, err := r.db.Query(context, query, userId)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := make([]model.Project, 0)
+	for rows.Next() {
+		var project model.Project
+		if err = rows.Scan(&project.ProjectId, &project.Created, &project.Updated, &project.Name, &project.MediaType,
+			&project.VideoPath, &project.ImagePath, &project.UserId, &project.Version, &project.DeletedAt); err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+
+		projects = append(projects, project)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+// RestoreProject takes user's project out of trash and returns its new version
+func (r *RepositoryPostgres) RestoreProject(context context.Context, userId, projectId uuid.UUID) (int64, error) {
+	query := `
+	UPDATE project SET deleted_at = NULL, version = version + 1, updated = now()
+	WHERE project_id = $1 AND user_id = $2 AND deleted_at IS NOT NULL
+	RETURNING version;
+	`
+
+	var version int64
+	row := r.db.QueryRow(context, query, projectId, userId)
+	if err := row.Scan(&version); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, model.NotFound
+		}
+		r.logger.Error(err)
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// GetExpiredProjects returns projects which were deleted before given time
+func (r *RepositoryPostgres) GetExpiredProjects(context context.Context, deletedBefore time.Time) ([]uuid.UUID, error) {
+	query := `SELECT project_id FROM project WHERE deleted_at < $1;`
+
+	rows, err := r.db.Query(context, query, deletedBefore)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var projectId uuid.UUID
+		if err = rows.Scan(&projectId); err != nil {
+			r.logger.Error(err)
+			return nil, err
+		}
+
+		ids = append(ids, projectId)
+	}
+
+	return ids, rows.Err()
+}
+
+// PurgeProject permanently deletes project from trash together with its audio parts, notes and lexicon.
+// Media files of project which are not used by other projects are returned, so they can be removed.
+func (r *RepositoryPostgres) PurgeProject(context context.Context, projectId uuid.UUID) ([]string, error) {
+	tx, err := r.db.Begin(context)
+	if err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+	defer tx.Rollback(context)
+
+	query := `
+	SELECT ARRAY(
+		SELECT unnest(ARRAY[video_path, audio_path, image_path, proxy_path, hls_path, output_path, output_hls_path,
+			thumbnails_path])
+		FROM project WHERE project_id = $1
+		UNION
+		SELECT path FROM audio_part WHERE project_id = $1
+	)
+	`
+
+	var files []string
+	if err = tx.QueryRow(context, query, projectId).Scan(&files); err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+
+	query = `DELETE FROM project WHERE project_id = $1 AND deleted_at IS NOT NULL RETURNING project_id;`
+	if err = tx.QueryRow(context, query, projectId).Scan(&projectId); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.NotFound
+		}
+		r.logger.Error(err)
+		return nil, err
+	}
+
+	// duplicated projects may share media files
+	query = `
+	SELECT ARRAY(
+		SELECT f FROM unnest($1::text[]) f
+		WHERE f IS NOT NULL AND f <> ''
+			AND NOT EXISTS (SELECT 1 FROM project
+				WHERE f IN (video_path, audio_path, image_path, proxy_path, hls_path, output_path, output_hls_path,
+					thumbnails_path))
+			AND NOT EXISTS (SELECT 1 FROM audio_part WHERE path = f)
+	)
+	`
+
+	var unused []string
+	if err = tx.QueryRow(context, query, files).Scan(&unused); err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+
+	if err = tx.Commit(context); err != nil {
+		r.logger.Error(err)
+		return nil, err
+	}
+
+	return unused, nil
+}
@@ -0,0 +1,107 @@
+package trash
+
+import (
+	"context"
+	"path"
+	"strings"
+	"time"
+
+	"tiflo/internal/repository"
+	"tiflo/model"
+	"tiflo/pkg/storage"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+type Config struct {
+	// Retention is how long deleted project stays in trash
+	Retention time.Duration
+	// PurgeInterval is how often expired projects are purged
+	PurgeInterval time.Duration
+}
+
+func InitConfig(vp *viper.Viper) Config {
+	config := Config{
+		Retention:     30 * 24 * time.Hour,
+		PurgeInterval: time.Hour,
+	}
+
+	if vp.IsSet("trash.retention") {
+		config.Retention = vp.GetDuration("trash.retention")
+	}
+	if interval := vp.GetDuration("trash.purgeInterval"); interval > 0 {
+		config.PurgeInterval = interval
+	}
+
+	return config
+}
+
+// Purger permanently deletes projects which stay in trash longer than retention period
+type Purger struct {
+	repo    repository.Repository
+	config  Config
+	storage storage.Storage
+	logger  *logrus.Entry
+}
+
+func NewPurger(logger *logrus.Logger, repo repository.Repository, config Config, storage storage.Storage) *Purger {
+	return &Purger{
+		repo:    repo,
+		config:  config,
+		storage: storage,
+		logger:  logger.WithField("component", "trash-purger"),
+	}
+}
+
+// Run purges trash every PurgeInterval until ctx is done
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.config.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		if purged, err := p.Purge(ctx); err != nil {
+			p.logger.Error("purge trash: ", err)
+		} else if purged > 0 {
+			p.logger.Infof("purged %d projects", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge deletes expired projects and their media files, number of purged projects is returned
+func (p *Purger) Purge(ctx context.Context) (int, error) {
+	ids, err := p.repo.GetExpiredProjects(ctx, time.Now().Add(-p.config.Retention))
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, projectId := range ids {
+		files, err := p.repo.PurgeProject(ctx, projectId)
+		if err != nil {
+			p.logger.Errorf("purge project %s: %s", projectId, err)
+			continue
+		}
+		purged++
+
+		for _, file := range files {
+			if strings.HasPrefix(file, model.HLSDir) || strings.HasPrefix(file, model.ThumbnailsDir) {
+				// HLS ladder and thumbnails are deleted with all files their index refers to
+				err = storage.DeleteDir(ctx, p.storage, path.Dir(file))
+			} else {
+				err = p.storage.Delete(ctx, file)
+			}
+			if err != nil {
+				p.logger.Errorf("remove media file %s: %s", file, err)
+			}
+		}
+	}
+
+	return purged, nil
+}
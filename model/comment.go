@@ -1,5 +1,29 @@
 package model
 
+// CaptionFrame is frame sent to captioning, Offset is its time relative to commented moment in tenths of a second
+type CaptionFrame struct {
+	Path   string
+	Offset int64
+}
+
+// FrameContext is what video comment is captioned from: frames around commented moment ordered by time
+// and descriptions of neighbouring audio parts
+type FrameContext struct {
+	Frames       []CaptionFrame
+	PreviousText string
+	NextText     string
+}
+
+// TextBlock is on-screen text recognised in image, bounding box is given in pixels
+type TextBlock struct {
+	Text       string
+	Confidence float32
+	X          int
+	Y          int
+	Width      int
+	Height     int
+}
+
 type Comment struct {
 	SplitPoint string `json:"splitPoint"`
 	VideoTime  string `json:"videoTime"`
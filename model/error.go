@@ -6,4 +6,10 @@ var (
 	Conflict      = errors.New("Conflict")
 	NotFound      = errors.New("NotFound")
 	InternalError = errors.New("InternalError")
+
+	VersionMismatch = errors.New("VersionMismatch")
+	LockLost        = errors.New("LockLost")
+
+	OffsetMismatch = errors.New("OffsetMismatch")
+	InvalidMedia   = errors.New("InvalidMedia")
 )
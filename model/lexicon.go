@@ -0,0 +1,39 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	AlphabetIPA    = "ipa"
+	AlphabetXSampa = "x-sampa"
+)
+
+// LexiconEntry tells TTS how to pronounce term. Entry without ProjectId belongs to user and is used
+// in all user's projects, project entries override user entries with the same term.
+type LexiconEntry struct {
+	EntryId   uuid.UUID  `json:"entryId"`
+	UserId    uuid.UUID  `json:"userId"`
+	ProjectId *uuid.UUID `json:"projectId,omitempty"`
+	Term      string     `json:"term"`
+	// Alias is text which is read instead of term
+	Alias string `json:"alias,omitempty"`
+	// Phoneme is pronunciation of term in Alphabet, it is used only in SSML mode
+	Phoneme  string    `json:"phoneme,omitempty"`
+	Alphabet string    `json:"alphabet,omitempty"`
+	Created  time.Time `json:"created"`
+}
+
+type LexiconEntryCreate struct {
+	Term     string `json:"term" binding:"required"`
+	Alias    string `json:"alias"`
+	Phoneme  string `json:"phoneme"`
+	Alphabet string `json:"alphabet"`
+}
+
+type VoiceSettings struct {
+	SSML bool `json:"ssml"`
+	// Voice is name of TTS voice, empty means default voice
+	Voice string `json:"voice"`
+}
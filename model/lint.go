@@ -0,0 +1,41 @@
+package model
+
+import "github.com/google/uuid"
+
+const (
+	LintRuleWordsPerSecond = "words_per_second"
+	LintRuleMaxLength      = "max_length"
+	LintRuleBannedPhrase   = "banned_phrase"
+	LintRuleSubjective     = "subjective"
+	LintRuleRepetition     = "repetition"
+)
+
+// LintConfig is a style guide for descriptions. Organisation style guide is taken from config,
+// project can override it with its own one.
+type LintConfig struct {
+	// MaxWordsPerSecond is compared with duration of voiced part
+	MaxWordsPerSecond float64 `json:"maxWordsPerSecond"`
+	// MaxLength is max count of characters in description
+	MaxLength int `json:"maxLength"`
+	// BannedPhrases are matched case-insensitively by whole words
+	BannedPhrases []string `json:"bannedPhrases"`
+	// SubjectiveWords are word stems, every word which starts with stem is reported
+	SubjectiveWords []string `json:"subjectiveWords"`
+	// RepeatedWords is length of word sequence which should not be repeated in neighbouring parts
+	RepeatedWords int      `json:"repeatedWords"`
+	DisabledRules []string `json:"disabledRules"`
+}
+
+// LintedProject is project returned together with warnings of descriptions which were just generated
+type LintedProject struct {
+	Project
+	Warnings []LintWarning `json:"warnings"`
+}
+
+type LintWarning struct {
+	PartId  uuid.UUID `json:"partId"`
+	Rule    string    `json:"rule"`
+	Message string    `json:"message"`
+	// Fragment is a part of text which caused warning
+	Fragment string `json:"fragment,omitempty"`
+}
@@ -0,0 +1,14 @@
+package model
+
+import "github.com/gin-gonic/gin"
+
+const (
+	LockTokenCtx = "LockToken"
+	// SessionIdHeader is set by client to id of its tab or session, it becomes holder of project edit lock
+	SessionIdHeader = "X-Session-Id"
+)
+
+// GetLockToken returns fencing token of project edit lock, 0 means that request doesn't hold the lock
+func GetLockToken(context *gin.Context) int64 {
+	return context.GetInt64(LockTokenCtx)
+}
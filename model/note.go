@@ -0,0 +1,58 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Note is reviewer comment anchored to audio part or to video timestamp. Time of note anchored to part is kept
+// on described timeline as AudioPart.Start and moves together with part. Time of note anchored to timestamp
+// is kept in time of original video, so inserted descriptions don't move it away from its moment,
+// see VideoTime and TimelineTime. Notes are returned to client with time on described timeline.
+type Note struct {
+	NoteId     uuid.UUID   `json:"noteId"`
+	ProjectId  uuid.UUID   `json:"projectId"`
+	ParentId   *uuid.UUID  `json:"parentId,omitempty"`
+	PartId     *uuid.UUID  `json:"partId,omitempty"`
+	Time       int64       `json:"time"`
+	UserId     uuid.UUID   `json:"userId"`
+	Text       string      `json:"text"`
+	Created    time.Time   `json:"created"`
+	Resolved   bool        `json:"resolved"`
+	ResolvedBy *uuid.UUID  `json:"resolvedBy,omitempty"`
+	ResolvedAt *time.Time  `json:"resolvedAt,omitempty"`
+	Mentions   []uuid.UUID `json:"mentions"`
+	Replies    []Note      `json:"replies,omitempty"`
+}
+
+type NoteCreate struct {
+	ParentId *uuid.UUID  `json:"parentId"`
+	PartId   *uuid.UUID  `json:"partId"`
+	Time     string      `json:"time"`
+	Text     string      `json:"text" binding:"required"`
+	Mentions []uuid.UUID `json:"mentions"`
+}
+
+type NoteResolve struct {
+	Resolved bool `json:"resolved"`
+}
+
+// BuildNoteThreads groups replies under their root notes, notes have to be sorted by creation time
+func BuildNoteThreads(notes []Note) []Note {
+	replies := map[uuid.UUID][]Note{}
+	for _, note := range notes {
+		if note.ParentId != nil {
+			replies[*note.ParentId] = append(replies[*note.ParentId], note)
+		}
+	}
+
+	threads := make([]Note, 0)
+	for _, note := range notes {
+		if note.ParentId == nil {
+			note.Replies = replies[note.NoteId]
+			threads = append(threads, note)
+		}
+	}
+
+	return threads
+}
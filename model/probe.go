@@ -0,0 +1,139 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MediaProbe is metadata of uploaded file which was read by ffprobe
+type MediaProbe struct {
+	// Container is format name reported by ffprobe, e.g. "mov,mp4,m4a,3gp,3g2,mj2"
+	Container string `json:"container"`
+	// Duration in tenths of a second, as start and duration of audio parts
+	Duration int64        `json:"duration"`
+	Size     int64        `json:"size"`
+	BitRate  int64        `json:"bitRate"`
+	Video    *VideoStream `json:"video,omitempty"`
+	Audio    *AudioStream `json:"audio,omitempty"`
+}
+
+type VideoStream struct {
+	Codec     string  `json:"codec"`
+	Width     int     `json:"width"`
+	Height    int     `json:"height"`
+	FrameRate float64 `json:"frameRate"`
+}
+
+type AudioStream struct {
+	Codec      string `json:"codec"`
+	SampleRate int    `json:"sampleRate"`
+	Channels   int    `json:"channels"`
+}
+
+// MediaLimits restrict uploaded files, zero and empty values mean no restriction
+type MediaLimits struct {
+	MaxSize         int64
+	MaxDuration     time.Duration
+	MaxWidth        int
+	MaxHeight       int
+	VideoContainers []string
+	AudioContainers []string
+	VideoCodecs     []string
+	AudioCodecs     []string
+	ImageCodecs     []string
+}
+
+func DefaultMediaLimits() MediaLimits {
+	return MediaLimits{
+		MaxDuration:     4 * time.Hour,
+		MaxWidth:        3840,
+		MaxHeight:       2160,
+		VideoContainers: []string{"mp4", "mov", "matroska", "webm"},
+		AudioContainers: []string{"wav", "mp3", "flac"},
+		VideoCodecs:     []string{"h264", "hevc", "mpeg4", "vp8", "vp9", "av1", "prores"},
+		AudioCodecs: []string{"aac", "mp3", "opus", "vorbis", "ac3", "eac3", "flac", "pcm_s16le", "pcm_s24le",
+			"pcm_f32le"},
+		ImageCodecs: []string{"png", "mjpeg"},
+	}
+}
+
+func invalidMedia(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", InvalidMedia, fmt.Sprintf(format, args...))
+}
+
+func allowed(codecs []string, codec string) bool {
+	if len(codecs) == 0 {
+		return true
+	}
+
+	for _, c := range codecs {
+		if strings.EqualFold(c, codec) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowedContainer checks format name of ffprobe, which lists all names of format, e.g. "matroska,webm"
+func allowedContainer(containers []string, container string) bool {
+	for _, name := range strings.Split(container, ",") {
+		if allowed(containers, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Check validates probed file against limits, mediaType is type which file is uploaded as.
+// Video and audio have to contain audio track, because descriptions are placed on it.
+func (l MediaLimits) Check(probe MediaProbe, mediaType string) error {
+	if l.MaxSize > 0 && probe.Size > l.MaxSize {
+		return invalidMedia("размер файла больше %d байт", l.MaxSize)
+	}
+
+	if mediaType != MediaTypeAudio {
+		if probe.Video == nil {
+			return invalidMedia("в файле нет изображения")
+		}
+		if (l.MaxWidth > 0 && probe.Video.Width > l.MaxWidth) || (l.MaxHeight > 0 && probe.Video.Height > l.MaxHeight) {
+			return invalidMedia("разрешение больше %dx%d", l.MaxWidth, l.MaxHeight)
+		}
+	}
+
+	switch mediaType {
+	case MediaTypeImage:
+		if !allowed(l.ImageCodecs, probe.Video.Codec) {
+			return invalidMedia("неподдерживаемый формат изображения %s", probe.Video.Codec)
+		}
+		return nil
+	case MediaTypeVideo:
+		if !allowedContainer(l.VideoContainers, probe.Container) {
+			return invalidMedia("неподдерживаемый контейнер %s", probe.Container)
+		}
+		if !allowed(l.VideoCodecs, probe.Video.Codec) {
+			return invalidMedia("неподдерживаемый видеокодек %s", probe.Video.Codec)
+		}
+	case MediaTypeAudio:
+		if !allowedContainer(l.AudioContainers, probe.Container) {
+			return invalidMedia("неподдерживаемый контейнер %s", probe.Container)
+		}
+	}
+
+	if probe.Audio == nil {
+		return invalidMedia("в файле нет звуковой дорожки")
+	}
+	if !allowed(l.AudioCodecs, probe.Audio.Codec) {
+		return invalidMedia("неподдерживаемый аудиокодек %s", probe.Audio.Codec)
+	}
+	if probe.Duration <= 0 {
+		return invalidMedia("не удалось определить длительность")
+	}
+	if l.MaxDuration > 0 && probe.Duration > l.MaxDuration.Milliseconds()/100 {
+		return invalidMedia("длительность больше %s", l.MaxDuration)
+	}
+
+	return nil
+}
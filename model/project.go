@@ -1,34 +1,172 @@
 package model
 
 import (
+	"errors"
+	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"sort"
 	"time"
 )
 
+const (
+	ProjectCtx = "Project"
+
+	// HLSDir keeps HLS ladders, every ladder is a directory with HLSMasterPlaylist and playlists of renditions
+	HLSDir            = "hls/"
+	HLSMasterPlaylist = "master.m3u8"
+	// ThumbnailsDir keeps sprite sheets of video thumbnails, every directory has WebVTT index ThumbnailsIndex
+	ThumbnailsDir   = "thumbnails/"
+	ThumbnailsIndex = "index.vtt"
+)
+
 type AudioPart struct {
-	PartId    uuid.UUID `json:"partId"`
-	ProjectId uuid.UUID `json:"projectId"`
-	Start     int64     `json:"start"`
-	Duration  int64     `json:"duration"`
-	Text      string    `json:"text"`
-	Path      string    `json:"path"`
+	PartId     uuid.UUID `json:"partId"`
+	ProjectId  uuid.UUID `json:"projectId"`
+	Start      int64     `json:"start"`
+	Duration   int64     `json:"duration"`
+	Text       string    `json:"text"`
+	Path       string    `json:"path"`
+	VoiceInput string    `json:"-"`
+	Voice      string    `json:"-"`
+	Status     string    `json:"status"`
+	// ReviewerId is user who changed review status last, only project owner reviews until project has members
+	ReviewerId *uuid.UUID `json:"reviewerId,omitempty"`
+	Reviewed   *time.Time `json:"reviewed,omitempty"`
+	// Url is download URL of Path, it is filled in project info and list
+	Url string `json:"url,omitempty"`
 }
 
 type Project struct {
 	ProjectId  uuid.UUID   `json:"projectId" binding:"required"`
 	Created    time.Time   `json:"created"`
+	Updated    time.Time   `json:"updated"`
 	Name       string      `json:"name" binding:"required"`
+	MediaType  string      `json:"mediaType"`
 	VideoPath  string      `json:"path" binding:"required"`
 	AudioPath  string      `json:"-"`
 	ImagePath  string      `json:"previewPath"`
 	UserId     uuid.UUID   `json:"userId" binding:"required"`
+	Version    int64       `json:"version"`
+	LintConfig *LintConfig `json:"lintConfig,omitempty"`
+	SSML       bool        `json:"ssml"`
+	Voice      string      `json:"voice"`
+	IsTemplate bool        `json:"isTemplate"`
+	DeletedAt  *time.Time  `json:"deletedAt,omitempty"`
+	Probe      *MediaProbe `json:"probe,omitempty"`
+	// ProxyPath is low-bitrate mp4 editing proxy of video, it is played in browser instead of original
+	// which is kept as uploaded. HlsPath and OutputHlsPath are master playlists of uploaded
+	// media and of described audio made after render, they are made in background and may be empty
+	ProxyPath     string `json:"proxyPath,omitempty"`
+	HlsPath       string `json:"hlsPath,omitempty"`
+	OutputHlsPath string `json:"outputHlsPath,omitempty"`
+	// ThumbnailsPath is WebVTT index of thumbnail sprite sheets of video, it is made in background too
+	ThumbnailsPath string `json:"thumbnailsPath,omitempty"`
+	// OutputPath is described audio of the latest render
+	OutputPath string `json:"outputPath,omitempty"`
+	// VideoUrl and PreviewUrl are download URLs of VideoPath and ImagePath, they are filled in project info and list
+	VideoUrl   string `json:"videoUrl,omitempty"`
+	PreviewUrl string `json:"previewUrl,omitempty"`
+	// ProxyUrl, HlsUrl, OutputHlsUrl and ThumbnailsUrl are URLs of proxy, playlists and thumbnails index,
+	// they are filled in project info
+	ProxyUrl      string `json:"proxyUrl,omitempty"`
+	HlsUrl        string `json:"hlsUrl,omitempty"`
+	OutputHlsUrl  string `json:"outputHlsUrl,omitempty"`
+	ThumbnailsUrl string `json:"thumbnailsUrl,omitempty"`
+	OutputUrl     string `json:"outputUrl,omitempty"`
+	// Status is filled only in project list, see ProjectStatus* constants
+	Status     string      `json:"status,omitempty"`
 	AudioParts []AudioPart `json:"audioParts" binding:"omitempty"`
 }
 
+// TimelineUpdate is a set of audio part changes which are applied to project of given version at once
+type TimelineUpdate struct {
+	ProjectId    uuid.UUID
+	Version      int64
+	FencingToken int64
+	Deleted      []uuid.UUID
+	Updated      []AudioPart
+	// Moved re-anchors notes of deleted parts, moves are applied in order after parts are updated.
+	// Notes which are not moved lose their part and keep their moment of original video.
+	Moved []NoteMove
+}
+
+// NoteMove re-anchors notes of part which is deleted from timeline to part which takes its place.
+// Notes of From which are not earlier than Since are moved to To and shifted by Shift, new time is capped by Until.
+type NoteMove struct {
+	From  uuid.UUID
+	To    uuid.UUID
+	Since int64
+	Shift int64
+	Until int64
+}
+
 type VoiceText struct {
 	Text string `json:"text"`
 }
 
-type Image struct {
-	Name string `json:"name"`
+// GetProject returns project which was loaded and checked for ownership by project middleware
+func GetProject(context *gin.Context) (Project, error) {
+	value, exists := context.Get(ProjectCtx)
+	if !exists {
+		return Project{}, errors.New("no project in context")
+	}
+
+	project, ok := value.(Project)
+	if !ok {
+		return Project{}, errors.New("wrong project type in context")
+	}
+
+	return project, nil
+}
+
+// VoiceSettings returns settings which are used to voice project descriptions
+func (p Project) VoiceSettings() VoiceSettings {
+	return VoiceSettings{SSML: p.SSML, Voice: p.Voice}
+}
+
+// descriptions returns descriptions of project sorted by start, parts without text are fragments of original audio
+func descriptions(parts []AudioPart) []AudioPart {
+	result := make([]AudioPart, 0, len(parts))
+	for _, part := range parts {
+		if part.Text != "" {
+			result = append(result, part)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Start < result[j].Start
+	})
+
+	return result
+}
+
+// VideoTime maps time of described timeline to time of original video, time inside description is mapped
+// to the moment of video where description is inserted
+func VideoTime(parts []AudioPart, timelineTime int64) int64 {
+	videoTime := timelineTime
+	for _, part := range descriptions(parts) {
+		if part.Start >= timelineTime {
+			break
+		}
+		if end := part.Start + part.Duration; end < timelineTime {
+			videoTime -= part.Duration
+		} else {
+			videoTime -= timelineTime - part.Start
+		}
+	}
+
+	return videoTime
+}
+
+// TimelineTime maps time of original video to described timeline, descriptions inserted at that moment
+// are voiced before it
+func TimelineTime(parts []AudioPart, videoTime int64) int64 {
+	var described int64
+	for _, part := range descriptions(parts) {
+		if part.Start-described > videoTime {
+			break
+		}
+		described += part.Duration
+	}
+
+	return videoTime + described
 }
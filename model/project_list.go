@@ -0,0 +1,87 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+)
+
+const (
+	MediaTypeNone  = ""
+	MediaTypeVideo = "video"
+	MediaTypeImage = "image"
+	MediaTypeAudio = "audio"
+)
+
+// Status of project in list is derived from its media and audio parts
+const (
+	// ProjectStatusNew - media is not uploaded yet
+	ProjectStatusNew = "new"
+	// ProjectStatusDraft - media is uploaded, but there are no descriptions
+	ProjectStatusDraft = "draft"
+	// ProjectStatusInReview - some descriptions are not approved
+	ProjectStatusInReview = "in_review"
+	// ProjectStatusReady - all descriptions are approved
+	ProjectStatusReady = "ready"
+)
+
+const (
+	ProjectSortCreated = "created"
+	ProjectSortName    = "name"
+	ProjectSortUpdated = "updated"
+
+	ProjectListDefaultLimit = 20
+	ProjectListMaxLimit     = 100
+)
+
+var InvalidCursor = errors.New("invalid cursor")
+
+// ProjectListParams describes one page of user's projects
+type ProjectListParams struct {
+	UserId    uuid.UUID
+	Sort      string
+	Desc      bool
+	MediaType *string
+	Status    string
+	Search    string
+	Template  *bool
+	Limit     int
+	Cursor    *ProjectCursor
+	// Summary mode returns projects without audio parts
+	Summary bool
+}
+
+// ProjectCursor points to the last project of previous page. Sort and Desc are kept in cursor,
+// so it can't be used with other ordering.
+type ProjectCursor struct {
+	Sort      string    `json:"s"`
+	Desc      bool      `json:"d"`
+	Value     string    `json:"v"`
+	ProjectId uuid.UUID `json:"id"`
+}
+
+type ProjectList struct {
+	Projects   []Project `json:"projects"`
+	NextCursor string    `json:"nextCursor,omitempty"`
+}
+
+func (c ProjectCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeProjectCursor(cursor string) (ProjectCursor, error) {
+	var c ProjectCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return c, InvalidCursor
+	}
+
+	if err = json.Unmarshal(data, &c); err != nil {
+		return c, InvalidCursor
+	}
+
+	return c, nil
+}
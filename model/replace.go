@@ -0,0 +1,161 @@
+package model
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	ReplaceModeLiteral = "literal"
+	ReplaceModeRegex   = "regex"
+	// ReplaceModeWord replaces literal only when it is a whole word
+	ReplaceModeWord = "word"
+)
+
+var UnknownReplaceMode = errors.New("unknown replace mode")
+
+// ReplaceRequest is bulk find/replace over all project descriptions.
+// In regex mode Replace may reference groups as $1 or ${name}.
+type ReplaceRequest struct {
+	Find          string `json:"find" binding:"required"`
+	Replace       string `json:"replace"`
+	Mode          string `json:"mode"`
+	CaseSensitive bool   `json:"caseSensitive"`
+}
+
+// ReplacePreview shows how description of part changes
+type ReplacePreview struct {
+	PartId  uuid.UUID `json:"partId"`
+	Start   int64     `json:"start"`
+	Before  string    `json:"before"`
+	After   string    `json:"after"`
+	Matches int       `json:"matches"`
+}
+
+// Replacer applies compiled ReplaceRequest to texts
+type Replacer struct {
+	request ReplaceRequest
+	re      *regexp.Regexp
+}
+
+func NewReplacer(request ReplaceRequest) (*Replacer, error) {
+	if request.Mode == "" {
+		request.Mode = ReplaceModeLiteral
+	}
+
+	var pattern string
+	switch request.Mode {
+	case ReplaceModeLiteral, ReplaceModeWord:
+		pattern = regexp.QuoteMeta(request.Find)
+	case ReplaceModeRegex:
+		pattern = request.Find
+	default:
+		return nil, UnknownReplaceMode
+	}
+
+	if !request.CaseSensitive {
+		pattern = "(?i)" + pattern
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Replacer{request: request, re: re}, nil
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// isWholeWord checks that text[start:end] is not a part of longer word,
+// regexp \b can't be used as it knows only ASCII letters
+func isWholeWord(text string, start, end int) bool {
+	if start > 0 {
+		if r, _ := utf8.DecodeLastRuneInString(text[:start]); isWordRune(r) {
+			return false
+		}
+	}
+
+	if end < len(text) {
+		if r, _ := utf8.DecodeRuneInString(text[end:]); isWordRune(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Apply returns text with all matches replaced and number of replaced matches
+func (r *Replacer) Apply(text string) (string, int) {
+	var result []byte
+	var last, count int
+
+	for _, match := range r.re.FindAllStringSubmatchIndex(text, -1) {
+		// empty regex matches would insert replacement between every character
+		if match[0] == match[1] {
+			continue
+		}
+		if r.request.Mode == ReplaceModeWord && !isWholeWord(text, match[0], match[1]) {
+			continue
+		}
+
+		result = append(result, text[last:match[0]]...)
+		if r.request.Mode == ReplaceModeRegex {
+			result = r.re.ExpandString(result, r.request.Replace, text, match)
+		} else {
+			result = append(result, r.request.Replace...)
+		}
+		last = match[1]
+		count++
+	}
+
+	if count == 0 {
+		return text, 0
+	}
+
+	return string(append(result, text[last:]...)), count
+}
+
+// Preview returns changes of descriptions of project, parts without matches are skipped
+func (r *Replacer) Preview(project Project) []ReplacePreview {
+	previews := make([]ReplacePreview, 0)
+	for _, part := range project.AudioParts {
+		if part.Text == "" {
+			continue
+		}
+
+		after, count := r.Apply(part.Text)
+		if count == 0 || after == part.Text {
+			continue
+		}
+
+		previews = append(previews, ReplacePreview{
+			PartId:  part.PartId,
+			Start:   part.Start,
+			Before:  part.Text,
+			After:   after,
+			Matches: count,
+		})
+	}
+
+	return previews
+}
+
+// EmptiedParts returns parts whose descriptions become empty after replace. Part without text is a fragment
+// of original audio, so replace must not turn description into it.
+func EmptiedParts(previews []ReplacePreview) []uuid.UUID {
+	emptied := make([]uuid.UUID, 0)
+	for _, preview := range previews {
+		if strings.TrimSpace(preview.After) == "" {
+			emptied = append(emptied, preview.PartId)
+		}
+	}
+
+	return emptied
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"github.com/google/uuid"
+)
+
+// NotApprovedHeader marks render or export forced while some descriptions are not approved,
+// it holds number of such descriptions
+const NotApprovedHeader = "X-Not-Approved"
+
+const (
+	PartStatusDraft       = "draft"
+	PartStatusNeedsReview = "needs_review"
+	PartStatusApproved    = "approved"
+	PartStatusRejected    = "rejected"
+)
+
+// partStatusTransitions lists statuses which audio part can be moved to from each status
+var partStatusTransitions = map[string][]string{
+	PartStatusDraft:       {PartStatusNeedsReview},
+	PartStatusNeedsReview: {PartStatusApproved, PartStatusRejected, PartStatusDraft},
+	PartStatusApproved:    {PartStatusNeedsReview},
+	PartStatusRejected:    {PartStatusNeedsReview, PartStatusDraft},
+}
+
+func IsPartStatus(status string) bool {
+	_, ok := partStatusTransitions[status]
+	return ok
+}
+
+func CanChangePartStatus(from string, to string) bool {
+	for _, status := range partStatusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+type PartStatusUpdate struct {
+	Status string `json:"status" binding:"required"`
+}
+
+type DeliveryReadiness struct {
+	Ready       bool           `json:"ready"`
+	Total       int            `json:"total"`
+	Counts      map[string]int `json:"counts"`
+	NotApproved []uuid.UUID    `json:"notApproved"`
+}
+
+// GetDeliveryReadiness counts statuses of project descriptions. Parts without text are fragments
+// of original audio, they are not reviewed.
+func GetDeliveryReadiness(project Project) DeliveryReadiness {
+	readiness := DeliveryReadiness{
+		Counts:      map[string]int{},
+		NotApproved: []uuid.UUID{},
+	}
+
+	for _, part := range project.AudioParts {
+		if part.Text == "" {
+			continue
+		}
+
+		readiness.Total++
+		readiness.Counts[part.Status]++
+		if part.Status != PartStatusApproved {
+			readiness.NotApproved = append(readiness.NotApproved, part.PartId)
+		}
+	}
+
+	readiness.Ready = len(readiness.NotApproved) == 0
+
+	return readiness
+}
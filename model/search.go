@@ -0,0 +1,39 @@
+package model
+
+import "github.com/google/uuid"
+
+const (
+	SearchLanguageRussian = "ru"
+	SearchLanguageEnglish = "en"
+
+	SearchDefaultLimit = 20
+	SearchMaxLimit     = 100
+)
+
+// SearchParams describes full-text search over descriptions of user's projects.
+// Empty Language means that both Russian and English configurations are used.
+type SearchParams struct {
+	UserId    uuid.UUID
+	Query     string
+	Language  string
+	ProjectId *uuid.UUID
+	Limit     int
+	Offset    int
+}
+
+type SearchHit struct {
+	ProjectId   uuid.UUID `json:"projectId"`
+	ProjectName string    `json:"projectName"`
+	PartId      uuid.UUID `json:"partId"`
+	// Start is in tenths of a second, Time is the same in hh:mm:ss.ms format
+	Start int64  `json:"start"`
+	Time  string `json:"time"`
+	// VideoTime is moment of original media where description is inserted, it is Start without durations
+	// of descriptions which go before
+	VideoTime int64  `json:"videoTime"`
+	Text      string `json:"text"`
+	// Snippet is HTML escaped fragment of text where matches are wrapped in <mark>
+	Snippet    string  `json:"snippet"`
+	Rank       float32 `json:"rank"`
+	PreviewUrl string  `json:"previewUrl"`
+}
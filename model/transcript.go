@@ -0,0 +1,18 @@
+package model
+
+import "github.com/google/uuid"
+
+const (
+	TranscriptFormatHTML = "html"
+	TranscriptFormatText = "txt"
+)
+
+// TranscriptSegment is dialogue recognised in audio of project, Start and Duration are given in tenths of a second
+type TranscriptSegment struct {
+	SegmentId uuid.UUID `json:"segmentId"`
+	ProjectId uuid.UUID `json:"projectId"`
+	Start     int64     `json:"start"`
+	Duration  int64     `json:"duration"`
+	Text      string    `json:"text"`
+	Speaker   string    `json:"speaker,omitempty"`
+}
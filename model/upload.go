@@ -0,0 +1,34 @@
+package model
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	TusVersion    = "1.0.0"
+	TusExtensions = "creation,termination"
+
+	// UploadChunksDir is storage directory of chunks of unfinished uploads
+	UploadChunksDir = "uploads/"
+)
+
+// Upload is resumable upload of project media, its chunks are kept in storage until all of them are received
+type Upload struct {
+	UploadId  uuid.UUID `json:"uploadId"`
+	ProjectId uuid.UUID `json:"projectId"`
+	UserId    uuid.UUID `json:"userId"`
+	Filename  string    `json:"filename"`
+	Length    int64     `json:"length"`
+	Created   time.Time `json:"created"`
+	Offset    int64     `json:"-"`
+	Parts     []string  `json:"-"`
+}
+
+// ChunkName returns new name for chunk which starts at offset, names are unique,
+// so concurrent requests with the same offset never overwrite each other's chunks
+func (u Upload) ChunkName(offset int64) string {
+	return UploadChunksDir + u.UploadId.String() + "/" + strconv.FormatInt(offset, 10) + "-" + uuid.New().String()
+}
@@ -0,0 +1,497 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"tiflo/model"
+	"tiflo/pkg/storage"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// FormatVersion is incremented on every incompatible change of manifest
+	FormatVersion = 1
+
+	FormatZip = "zip"
+	FormatTar = "tar"
+
+	manifestName = "manifest.json"
+	mediaDir     = "media/"
+)
+
+var (
+	UnknownFormat      = errors.New("unknown archive format")
+	UnsupportedVersion = errors.New("unsupported archive version")
+	BrokenArchive      = errors.New("archive is broken")
+)
+
+// Manifest describes exported project. Ids and media names are the ones of source instance,
+// import replaces them with new ones.
+type Manifest struct {
+	FormatVersion int            `json:"formatVersion"`
+	Exported      time.Time      `json:"exported"`
+	Project       Project        `json:"project"`
+	AudioParts    []AudioPart    `json:"audioParts"`
+	Lexicon       []LexiconEntry `json:"lexicon"`
+	Files         []File         `json:"files"`
+}
+
+type Project struct {
+	ProjectId  uuid.UUID         `json:"projectId"`
+	Name       string            `json:"name"`
+	MediaType  string            `json:"mediaType"`
+	VideoPath  string            `json:"videoPath"`
+	AudioPath  string            `json:"audioPath"`
+	ImagePath  string            `json:"imagePath"`
+	LintConfig *model.LintConfig `json:"lintConfig,omitempty"`
+	SSML       bool              `json:"ssml"`
+	Voice      string            `json:"voice"`
+}
+
+type AudioPart struct {
+	PartId     uuid.UUID `json:"partId"`
+	Start      int64     `json:"start"`
+	Duration   int64     `json:"duration"`
+	Text       string    `json:"text"`
+	Path       string    `json:"path"`
+	VoiceInput string    `json:"voiceInput"`
+	Voice      string    `json:"voice"`
+	Status     string    `json:"status"`
+}
+
+type LexiconEntry struct {
+	Term     string `json:"term"`
+	Alias    string `json:"alias,omitempty"`
+	Phoneme  string `json:"phoneme,omitempty"`
+	Alphabet string `json:"alphabet,omitempty"`
+}
+
+type File struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+type Archiver interface {
+	// Export writes project, its lexicon and media files to w
+	Export(ctx context.Context, w io.Writer, format string, project model.Project, lexicon []model.LexiconEntry) error
+	// Import extracts media files of archive under new names and returns project which refers to them,
+	// project and parts get new ids. Project is not saved.
+	Import(ctx context.Context, r io.ReaderAt, size int64, format string, userId uuid.UUID) (model.Project, []model.LexiconEntry, error)
+}
+
+type ArchiverImpl struct {
+	storage storage.Storage
+	logger  *logrus.Entry
+}
+
+func NewArchiver(storage storage.Storage, logger *logrus.Logger) Archiver {
+	return &ArchiverImpl{storage: storage, logger: logger.WithField("component", "archiver")}
+}
+
+// entryWriter hides difference between zip and tar writers
+type entryWriter interface {
+	create(name string, size int64) (io.Writer, error)
+	Close() error
+}
+
+type zipWriter struct{ *zip.Writer }
+
+func (w zipWriter) create(name string, _ int64) (io.Writer, error) {
+	return w.Create(name)
+}
+
+type tarWriter struct{ *tar.Writer }
+
+func (w tarWriter) create(name string, size int64) (io.Writer, error) {
+	err := w.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: size, ModTime: time.Now(), Typeflag: tar.TypeReg})
+	return w.Writer, err
+}
+
+func newEntryWriter(w io.Writer, format string) (entryWriter, error) {
+	switch format {
+	case FormatZip:
+		return zipWriter{zip.NewWriter(w)}, nil
+	case FormatTar:
+		return tarWriter{tar.NewWriter(w)}, nil
+	default:
+		return nil, UnknownFormat
+	}
+}
+
+func (a *ArchiverImpl) checksum(ctx context.Context, name string) (File, error) {
+	file, err := a.storage.Get(ctx, name)
+	if err != nil {
+		return File{}, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return File{}, err
+	}
+
+	return File{Name: name, Size: size, SHA256: hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+func (a *ArchiverImpl) buildManifest(ctx context.Context, project model.Project, lexicon []model.LexiconEntry) (Manifest, error) {
+	manifest := Manifest{
+		FormatVersion: FormatVersion,
+		Exported:      time.Now().UTC(),
+		Project: Project{
+			ProjectId:  project.ProjectId,
+			Name:       project.Name,
+			MediaType:  project.MediaType,
+			VideoPath:  project.VideoPath,
+			AudioPath:  project.AudioPath,
+			ImagePath:  project.ImagePath,
+			LintConfig: project.LintConfig,
+			SSML:       project.SSML,
+			Voice:      project.Voice,
+		},
+		AudioParts: make([]AudioPart, 0, len(project.AudioParts)),
+		Lexicon:    make([]LexiconEntry, 0, len(lexicon)),
+		Files:      make([]File, 0),
+	}
+
+	names := []string{project.VideoPath, project.AudioPath, project.ImagePath}
+	for _, part := range project.AudioParts {
+		manifest.AudioParts = append(manifest.AudioParts, AudioPart{
+			PartId:     part.PartId,
+			Start:      part.Start,
+			Duration:   part.Duration,
+			Text:       part.Text,
+			Path:       part.Path,
+			VoiceInput: part.VoiceInput,
+			Voice:      part.Voice,
+			Status:     part.Status,
+		})
+		names = append(names, part.Path)
+	}
+
+	for _, entry := range lexicon {
+		manifest.Lexicon = append(manifest.Lexicon, LexiconEntry{
+			Term:     entry.Term,
+			Alias:    entry.Alias,
+			Phoneme:  entry.Phoneme,
+			Alphabet: entry.Alphabet,
+		})
+	}
+
+	added := make(map[string]bool)
+	for _, name := range names {
+		if name == "" || added[name] {
+			continue
+		}
+		added[name] = true
+
+		file, err := a.checksum(ctx, name)
+		if err != nil {
+			return Manifest{}, fmt.Errorf("media file %s: %w", name, err)
+		}
+		manifest.Files = append(manifest.Files, file)
+	}
+
+	return manifest, nil
+}
+
+// Export writes manifest as the first entry, so tar archive can be imported in one pass
+func (a *ArchiverImpl) Export(ctx context.Context, w io.Writer, format string, project model.Project, lexicon []model.LexiconEntry) error {
+	manifest, err := a.buildManifest(ctx, project, lexicon)
+	if err != nil {
+		a.logger.Error(err)
+		return err
+	}
+
+	archive, err := newEntryWriter(w, format)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	entry, err := archive.create(manifestName, int64(len(data)))
+	if err != nil {
+		return err
+	}
+	if _, err = entry.Write(data); err != nil {
+		return err
+	}
+
+	for _, file := range manifest.Files {
+		if err = a.writeFile(ctx, archive, file); err != nil {
+			a.logger.Error(err)
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+func (a *ArchiverImpl) writeFile(ctx context.Context, archive entryWriter, file File) error {
+	src, err := a.storage.Get(ctx, file.Name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	entry, err := archive.create(mediaDir+file.Name, file.Size)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.CopyN(entry, src, file.Size)
+	return err
+}
+
+// importer extracts media files listed in manifest and remembers their new names
+type importer struct {
+	ctx     context.Context
+	storage storage.Storage
+	files   map[string]File
+	names   map[string]string
+}
+
+func (i *importer) readManifest(r io.Reader) (Manifest, error) {
+	var manifest Manifest
+	if err := json.NewDecoder(r).Decode(&manifest); err != nil {
+		return Manifest{}, BrokenArchive
+	}
+
+	if manifest.FormatVersion < 1 || manifest.FormatVersion > FormatVersion {
+		return Manifest{}, UnsupportedVersion
+	}
+
+	i.files = make(map[string]File, len(manifest.Files))
+	i.names = make(map[string]string, len(manifest.Files))
+	for _, file := range manifest.Files {
+		// names are used as paths in media directory, so only plain file names are accepted
+		if file.Name == "" || file.Name != path.Base(file.Name) || strings.ContainsAny(file.Name, `/\`) {
+			return Manifest{}, BrokenArchive
+		}
+		i.files[file.Name] = file
+	}
+
+	return manifest, nil
+}
+
+// extract saves archive entry under new name and checks its size and checksum
+func (i *importer) extract(entryName string, r io.Reader) error {
+	if !strings.HasPrefix(entryName, mediaDir) {
+		return nil
+	}
+
+	file, ok := i.files[strings.TrimPrefix(entryName, mediaDir)]
+	if !ok {
+		return nil
+	}
+	if _, done := i.names[file.Name]; done {
+		return BrokenArchive
+	}
+
+	newName := uuid.New().String() + filepath.Ext(file.Name)
+	i.names[file.Name] = newName
+
+	hash := sha256.New()
+	counter := &countingWriter{}
+	src := io.TeeReader(io.LimitReader(r, file.Size), io.MultiWriter(hash, counter))
+	if err := i.storage.Put(i.ctx, newName, src, file.Size); err != nil {
+		return err
+	}
+
+	// entry must not be longer than size from manifest
+	extra, _ := io.CopyN(io.Discard, r, 1)
+	if counter.size != file.Size || extra != 0 || hex.EncodeToString(hash.Sum(nil)) != file.SHA256 {
+		return BrokenArchive
+	}
+
+	return nil
+}
+
+type countingWriter struct {
+	size int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.size += int64(len(p))
+	return len(p), nil
+}
+
+func (i *importer) cleanup() {
+	for _, name := range i.names {
+		i.storage.Delete(i.ctx, name)
+	}
+}
+
+func (i *importer) rename(name string) string {
+	if name == "" {
+		return ""
+	}
+
+	return i.names[name]
+}
+
+func (a *ArchiverImpl) Import(ctx context.Context, r io.ReaderAt, size int64, format string, userId uuid.UUID) (model.Project, []model.LexiconEntry, error) {
+	imp := &importer{ctx: ctx, storage: a.storage}
+
+	var manifest Manifest
+	var err error
+	switch format {
+	case FormatZip:
+		manifest, err = imp.importZip(r, size)
+	case FormatTar:
+		manifest, err = imp.importTar(io.NewSectionReader(r, 0, size))
+	default:
+		return model.Project{}, nil, UnknownFormat
+	}
+
+	if err == nil && len(imp.names) != len(imp.files) {
+		err = BrokenArchive
+	}
+	if err != nil {
+		a.logger.Error("import: ", err)
+		imp.cleanup()
+		return model.Project{}, nil, err
+	}
+
+	project := model.Project{
+		ProjectId:  uuid.New(),
+		UserId:     userId,
+		Name:       manifest.Project.Name,
+		MediaType:  manifest.Project.MediaType,
+		VideoPath:  imp.rename(manifest.Project.VideoPath),
+		AudioPath:  imp.rename(manifest.Project.AudioPath),
+		ImagePath:  imp.rename(manifest.Project.ImagePath),
+		LintConfig: manifest.Project.LintConfig,
+		SSML:       manifest.Project.SSML,
+		Voice:      manifest.Project.Voice,
+		AudioParts: make([]model.AudioPart, 0, len(manifest.AudioParts)),
+	}
+
+	for _, part := range manifest.AudioParts {
+		if !model.IsPartStatus(part.Status) {
+			part.Status = model.PartStatusDraft
+		}
+
+		project.AudioParts = append(project.AudioParts, model.AudioPart{
+			PartId:     uuid.New(),
+			ProjectId:  project.ProjectId,
+			Start:      part.Start,
+			Duration:   part.Duration,
+			Text:       part.Text,
+			Path:       imp.rename(part.Path),
+			VoiceInput: part.VoiceInput,
+			Voice:      part.Voice,
+			Status:     part.Status,
+		})
+	}
+
+	lexicon := make([]model.LexiconEntry, 0, len(manifest.Lexicon))
+	for _, entry := range manifest.Lexicon {
+		lexicon = append(lexicon, model.LexiconEntry{
+			UserId:    userId,
+			ProjectId: &project.ProjectId,
+			Term:      entry.Term,
+			Alias:     entry.Alias,
+			Phoneme:   entry.Phoneme,
+			Alphabet:  entry.Alphabet,
+		})
+	}
+
+	return project, lexicon, nil
+}
+
+func (i *importer) importZip(r io.ReaderAt, size int64) (Manifest, error) {
+	reader, err := zip.NewReader(r, size)
+	if err != nil {
+		return Manifest{}, BrokenArchive
+	}
+
+	var manifest Manifest
+	found := false
+	for _, file := range reader.File {
+		if file.Name != manifestName {
+			continue
+		}
+
+		src, err := file.Open()
+		if err != nil {
+			return Manifest{}, BrokenArchive
+		}
+		manifest, err = i.readManifest(src)
+		src.Close()
+		if err != nil {
+			return Manifest{}, err
+		}
+		found = true
+		break
+	}
+	if !found {
+		return Manifest{}, BrokenArchive
+	}
+
+	for _, file := range reader.File {
+		src, err := file.Open()
+		if err != nil {
+			return Manifest{}, BrokenArchive
+		}
+		err = i.extract(file.Name, src)
+		src.Close()
+		if err != nil {
+			return Manifest{}, err
+		}
+	}
+
+	return manifest, nil
+}
+
+// importTar expects manifest to be the first entry as it is written by Export
+func (i *importer) importTar(r io.Reader) (Manifest, error) {
+	reader := tar.NewReader(r)
+
+	header, err := reader.Next()
+	if err != nil || header.Name != manifestName {
+		return Manifest{}, BrokenArchive
+	}
+
+	manifest, err := i.readManifest(reader)
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	for {
+		header, err = reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Manifest{}, BrokenArchive
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if err = i.extract(header.Name, reader); err != nil {
+			return Manifest{}, err
+		}
+	}
+
+	return manifest, nil
+}
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"time"
+)
+
+var (
+	ExpiredMediaUrl       = errors.New("media url expired")
+	InvalidMediaSignature = errors.New("invalid media url signature")
+)
+
+// MediaSigner signs names of media files with expiration time, so files can be downloaded
+// by URL without other authorization until it expires
+type MediaSigner interface {
+	Sign(name string, expires time.Time) string
+	Verify(name string, expires int64, signature string) error
+}
+
+type HMACMediaSigner struct {
+	key []byte
+}
+
+func NewMediaSigner(key string) (*HMACMediaSigner, error) {
+	if key == "" {
+		return nil, errors.New("empty media signing key")
+	}
+
+	return &HMACMediaSigner{key: []byte(key)}, nil
+}
+
+func (s *HMACMediaSigner) mac(name string, expires int64) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(name + "\n" + strconv.FormatInt(expires, 10)))
+	return mac.Sum(nil)
+}
+
+func (s *HMACMediaSigner) Sign(name string, expires time.Time) string {
+	return base64.RawURLEncoding.EncodeToString(s.mac(name, expires.Unix()))
+}
+
+func (s *HMACMediaSigner) Verify(name string, expires int64, signature string) error {
+	got, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(got, s.mac(name, expires)) {
+		return InvalidMediaSignature
+	}
+
+	if time.Now().Unix() > expires {
+		return ExpiredMediaUrl
+	}
+
+	return nil
+}
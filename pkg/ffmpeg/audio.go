@@ -1,9 +1,9 @@
 package ffmpeg
 
 import (
+	"context"
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"os/exec"
 	"sort"
@@ -30,19 +30,30 @@ import (
 //	start       splitPoint  duration1+splitPoint         duration1+end1
 //	     |           |             |                  |
 //	                 |  duration1  |
-func (s *MediaServiceImpl) SplitAudio(audioPartToSplit model.AudioPart, splitPointStr string, duration time.Duration) ([]model.AudioPart, error) {
+func (s *MediaServiceImpl) SplitAudio(ctx context.Context, audioPartToSplit model.AudioPart, splitPointStr string, duration time.Duration) ([]model.AudioPart, error) {
 	var result = make([]model.AudioPart, 0, 2)
 
+	ws, err := s.newWorkspace()
+	if err != nil {
+		return nil, err
+	}
+	defer ws.close()
+
+	input, err := ws.fetch(ctx, audioPartToSplit.Path)
+	if err != nil {
+		return nil, err
+	}
+
 	firstPartName := uuid.New()
 	start := audioPartToSplit.Start
 	splitPoint := s.ConvertTimeFromString(splitPointStr)
 	firstPartEnd := splitPoint - start
 
-	s.logger.Info("-ss ", "00:00:00.000", " -t ", s.convertTimeToString(firstPartEnd), s.pathForMedia+firstPartName.String()+".wav")
+	s.logger.Info("-ss ", "00:00:00.000", " -t ", s.ConvertTimeToString(firstPartEnd), firstPartName.String()+".wav")
 
-	_, err := exec.Command("ffmpeg", "-i", s.pathForMedia+audioPartToSplit.Path, "-vn", "-acodec", "pcm_s16le",
-		"-ss", "00:00:00.000", "-t", s.convertTimeToString(firstPartEnd),
-		s.pathForMedia+firstPartName.String()+".wav").Output()
+	_, err = exec.CommandContext(ctx, "ffmpeg", "-i", input, "-vn", "-acodec", "pcm_s16le",
+		"-ss", "00:00:00.000", "-t", s.ConvertTimeToString(firstPartEnd),
+		ws.path(firstPartName.String()+".wav")).Output()
 	if err != nil {
 		s.logger.Error(err)
 		return nil, err
@@ -57,12 +68,12 @@ func (s *MediaServiceImpl) SplitAudio(audioPartToSplit model.AudioPart, splitPoi
 	})
 
 	secondPartName := uuid.New()
-	s.logger.Info("-ss ", s.convertTimeToString(firstPartEnd), " -t ", s.convertTimeToString(start+audioPartToSplit.Duration-splitPoint),
-		s.pathForMedia+secondPartName.String()+".wav")
+	s.logger.Info("-ss ", s.ConvertTimeToString(firstPartEnd), " -t ", s.ConvertTimeToString(start+audioPartToSplit.Duration-splitPoint),
+		secondPartName.String()+".wav")
 
-	_, err = exec.Command("ffmpeg", "-i", s.pathForMedia+audioPartToSplit.Path, "-vn", "-acodec", "pcm_s16le",
-		"-ss", s.convertTimeToString(firstPartEnd), "-t", s.convertTimeToString(start+audioPartToSplit.Duration-splitPoint),
-		s.pathForMedia+secondPartName.String()+".wav").Output()
+	_, err = exec.CommandContext(ctx, "ffmpeg", "-i", input, "-vn", "-acodec", "pcm_s16le",
+		"-ss", s.ConvertTimeToString(firstPartEnd), "-t", s.ConvertTimeToString(start+audioPartToSplit.Duration-splitPoint),
+		ws.path(secondPartName.String()+".wav")).Output()
 	if err != nil {
 		s.logger.Error(err)
 		return nil, err
@@ -77,19 +88,13 @@ func (s *MediaServiceImpl) SplitAudio(audioPartToSplit model.AudioPart, splitPoi
 		Path:      secondPartName.String() + ".wav",
 	})
 
-	return result, nil
-}
-
-func (s *MediaServiceImpl) GetDuration(filename string) (time.Duration, error) {
-	file, err := os.Open(s.pathForMedia + filename)
-	if err != nil {
-		s.logger.Error("error while getting duration : ", err)
-		return time.Duration(0), err
+	for _, part := range result {
+		if err = ws.push(ctx, part.Path); err != nil {
+			return nil, err
+		}
 	}
-	defer file.Close()
 
-	decoder := wav.NewDecoder(file)
-	return decoder.Duration()
+	return result, nil
 }
 
 // timeString comes in format hh:mm:ss.ms
@@ -119,7 +124,8 @@ func (s *MediaServiceImpl) ConvertTimeFromString(timeString string) int64 {
 	return int64((hours*3600+minutes*60+seconds)*10 + milliseconds)
 }
 
-func (s *MediaServiceImpl) convertTimeToString(timeNum int64) string {
+// ConvertTimeToString formats time in tenths of a second as hh:mm:ss.ms
+func (s *MediaServiceImpl) ConvertTimeToString(timeNum int64) string {
 	milliseconds := (timeNum % 10) * 100
 	seconds := timeNum / 10 % 60
 	minutes := timeNum / 10 / 60 % 60
@@ -132,10 +138,23 @@ func (s *MediaServiceImpl) convertDurationToInt64(duration time.Duration) int64
 	return duration.Milliseconds() / 100
 }
 
-func (s *MediaServiceImpl) GetAudioDurationWav(audioPath string) (time.Duration, int64, error) {
+func (s *MediaServiceImpl) GetAudioDurationWav(ctx context.Context, audioPath string) (time.Duration, int64, error) {
 	var duration time.Duration
-	file, err := os.Open(s.pathForMedia + audioPath)
-	s.logger.Info(s.pathForMedia + audioPath)
+
+	ws, err := s.newWorkspace()
+	if err != nil {
+		return duration, 0, err
+	}
+	defer ws.close()
+
+	// wav decoder seeks over file, so it is read from workspace instead of storage stream
+	path, err := ws.fetch(ctx, audioPath)
+	if err != nil {
+		return duration, 0, err
+	}
+
+	file, err := os.Open(path)
+	s.logger.Info(audioPath)
 	if err != nil {
 		s.logger.Error(err)
 		return duration, 0, err
@@ -150,9 +169,9 @@ func (s *MediaServiceImpl) GetAudioDurationWav(audioPath string) (time.Duration,
 	return duration, durationInt, nil
 }
 
-func (s *MediaServiceImpl) GetAudioDurationMp3(audioPath string) (time.Duration, int64, error) {
+func (s *MediaServiceImpl) GetAudioDurationMp3(ctx context.Context, audioPath string) (time.Duration, int64, error) {
 	var duration time.Duration
-	file, err := os.Open(s.pathForMedia + audioPath)
+	file, err := s.storage.Get(ctx, audioPath)
 	if err != nil {
 		s.logger.Error(err)
 		return duration, 0, err
@@ -185,42 +204,50 @@ func (s *MediaServiceImpl) GetAudioDurationMp3(audioPath string) (time.Duration,
 // ConcatAudio ffmpeg -i audio1.wav -i audio2.wav -i audio3.wav -i audio4.wav -i audio5.wav \
 // -filter_complex '[0:0][1:0][2:0][3:0][4:0]concat=n=5:v=0:a=1[out]' \
 // -map '[out]' output.wav
-func (s *MediaServiceImpl) ConcatAudio(audioParts []model.AudioPart) (string, error) {
+func (s *MediaServiceImpl) ConcatAudio(ctx context.Context, audioParts []model.AudioPart) (string, error) {
 	sort.SliceStable(audioParts, func(i, j int) bool {
 		return audioParts[i].Start < audioParts[j].Start
 	})
 
+	ws, err := s.newWorkspace()
+	if err != nil {
+		return "", err
+	}
+	defer ws.close()
+
 	var arguments []string
 	var filter = ""
 
 	for i, part := range audioParts {
-		arguments = append(arguments, "-i", s.pathForMedia+part.Path)
+		input, err := ws.fetch(ctx, part.Path)
+		if err != nil {
+			return "", err
+		}
+
+		arguments = append(arguments, "-i", input)
 		filter += fmt.Sprintf("[%d:a]", i)
 	}
 
 	filter += fmt.Sprintf("concat=n=%d:v=0:a=1[out]", len(audioParts))
 
 	s.logger.Info(filter)
-	s.logger.Info(arguments)
-	concatAudio := uuid.New()
+	concatAudio := uuid.New().String() + ".wav"
 
-	arguments = append(arguments, "-filter_complex", filter, "-map", "[out]", s.pathForMedia+concatAudio.String()+".wav")
+	arguments = append(arguments, "-filter_complex", filter, "-map", "[out]", ws.path(concatAudio))
 
 	s.logger.Info(arguments)
-	//
-	//_, err := exec.Command("ffmpeg", arguments...).Output()
-	//if err != nil {
-	//	s.logger.Error(err)
-	//	return "", err
-	//}
-
-	cmd := exec.Command("ffmpeg", arguments...)
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", arguments...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	err := cmd.Run()
-	if err != nil {
-		log.Printf("FFmpeg command failed: %v", err)
+	if err = cmd.Run(); err != nil {
+		s.logger.Error("ffmpeg command failed: ", err)
+		return "", err
+	}
+
+	if err = ws.push(ctx, concatAudio); err != nil {
+		return "", err
 	}
 
-	return concatAudio.String() + ".wav", nil
+	return concatAudio, nil
 }
@@ -1,20 +1,82 @@
 package ffmpeg
 
 import (
+	"context"
+	"fmt"
 	"os/exec"
 
 	"github.com/google/uuid"
 )
 
-// ExtractFrame gets frame from mp4 file as png and returns its name
-func (s *MediaServiceImpl) ExtractFrame(videoPath string, timestamp string) (string, error) {
-	var frameName = uuid.New()
-	_, err := exec.Command("ffmpeg", "-i", videoPath, "-ss", timestamp, "-frames:v",
-		"1", s.pathForMedia+frameName.String()+".png").Output()
+// ExtractFrame gets frame from mp4 file as png, puts it to storage and returns its name
+func (s *MediaServiceImpl) ExtractFrame(ctx context.Context, videoPath string, timestamp string) (string, error) {
+	ws, err := s.newWorkspace()
+	if err != nil {
+		return "", err
+	}
+	defer ws.close()
+
+	input, err := ws.fetch(ctx, videoPath)
+	if err != nil {
+		return "", err
+	}
+
+	var frameName = uuid.New().String() + ".png"
+	_, err = exec.CommandContext(ctx, "ffmpeg", "-i", input, "-ss", timestamp, "-frames:v",
+		"1", ws.path(frameName)).Output()
 	if err != nil {
 		s.logger.Error("error while extracting frame: ", err)
 		return "", err
 	}
 
-	return frameName.String() + ".png", nil
+	if err = ws.push(ctx, frameName); err != nil {
+		return "", err
+	}
+
+	return frameName, nil
+}
+
+// ExtractFrames gets frames at every timestamp in one ffmpeg run, puts them to storage and returns their names
+// in order of timestamps
+func (s *MediaServiceImpl) ExtractFrames(ctx context.Context, videoPath string, timestamps []string) ([]string, error) {
+	ws, err := s.newWorkspace()
+	if err != nil {
+		return nil, err
+	}
+	defer ws.close()
+
+	input, err := ws.fetch(ctx, videoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// every frame is sought on its own input, so ffmpeg does not decode video between frames
+	var inputs, outputs []string
+	frameNames := make([]string, 0, len(timestamps))
+	for i, timestamp := range timestamps {
+		frameName := uuid.New().String() + ".png"
+		frameNames = append(frameNames, frameName)
+
+		inputs = append(inputs, "-ss", timestamp, "-i", input)
+		outputs = append(outputs, "-map", fmt.Sprintf("%d:v:0", i), "-frames:v", "1", ws.path(frameName))
+	}
+
+	_, err = exec.CommandContext(ctx, "ffmpeg", append(inputs, outputs...)...).Output()
+	if err != nil {
+		s.logger.Error("error while extracting frames: ", err)
+		return nil, err
+	}
+
+	for i, frameName := range frameNames {
+		if err = ws.push(ctx, frameName); err != nil {
+			for _, pushed := range frameNames[:i] {
+				if err := s.storage.Delete(ctx, pushed); err != nil {
+					s.logger.Error(err)
+				}
+			}
+			return nil, err
+		}
+	}
+
+	return frameNames, nil
 }
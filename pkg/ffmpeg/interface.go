@@ -1,30 +1,53 @@
 package ffmpeg
 
 import (
-	"tiflo/model"
+	"context"
 	"time"
 
+	"tiflo/model"
+	"tiflo/pkg/storage"
+	"tiflo/pkg/waveform"
+
 	"github.com/sirupsen/logrus"
 )
 
 type MediaService interface {
-	SplitAudio(audioPartToSplit model.AudioPart, splitPointStr string, duration time.Duration) ([]model.AudioPart, error)
-	ConcatAudio(audioParts []model.AudioPart) (string, error)
+	SplitAudio(ctx context.Context, audioPartToSplit model.AudioPart, splitPointStr string, duration time.Duration) ([]model.AudioPart, error)
+	ConcatAudio(ctx context.Context, audioParts []model.AudioPart) (string, error)
 
 	ConvertTimeFromString(timeString string) int64
+	ConvertTimeToString(timeNum int64) string
+
+	GetAudioDurationWav(ctx context.Context, audioPath string) (time.Duration, int64, error)
+	GetAudioDurationMp3(ctx context.Context, audioPath string) (time.Duration, int64, error)
+
+	Probe(ctx context.Context, name string) (model.MediaProbe, error)
+	GetAudioFromVideo(ctx context.Context, filename string, extension string) error
+	GetAudioFromAudio(ctx context.Context, name string) (string, error)
+	ExtractFrame(ctx context.Context, videoPath string, timestamp string) (string, error)
+	ExtractFrames(ctx context.Context, videoPath string, timestamps []string) ([]string, error)
 
-	GetAudioDurationWav(audioPath string) (time.Duration, int64, error)
-	GetAudioDurationMp3(audioPath string) (time.Duration, int64, error)
+	MakeProxy(ctx context.Context, name string) (string, error)
+	MakeHLS(ctx context.Context, videoName string, audioName string, height int) (string, error)
+	MakeThumbnails(ctx context.Context, videoName string, probe model.MediaProbe) (string, error)
 
-	GetAudioFromVideo(filename string, extension string) error
-	ExtractFrame(videoPath string, timestamp string) (string, error)
+	Waveform(ctx context.Context, name string, zooms []int) ([]waveform.Waveform, error)
 }
 
+// MediaServiceImpl runs ffmpeg over files from storage, inputs are staged to temporary
+// directory and results are pushed back to storage
 type MediaServiceImpl struct {
-	pathForMedia string
-	logger       *logrus.Entry
+	storage storage.Storage
+	tempDir string
+	preview PreviewConfig
+	logger  *logrus.Entry
 }
 
-func NewMediaService(pathForMedia string, logger *logrus.Logger) MediaService {
-	return &MediaServiceImpl{pathForMedia: pathForMedia, logger: logger.WithField("component", "media-service")}
+func NewMediaService(storage storage.Storage, tempDir string, preview PreviewConfig, logger *logrus.Logger) MediaService {
+	return &MediaServiceImpl{
+		storage: storage,
+		tempDir: tempDir,
+		preview: preview,
+		logger:  logger.WithField("component", "media-service"),
+	}
 }
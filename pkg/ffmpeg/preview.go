@@ -0,0 +1,177 @@
+package ffmpeg
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"tiflo/model"
+
+	"github.com/google/uuid"
+)
+
+// Rendition is one quality level of video, bitrates are given in ffmpeg notation, e.g. "800k"
+type Rendition struct {
+	Name         string
+	Height       int
+	VideoBitrate string
+	AudioBitrate string
+}
+
+type PreviewConfig struct {
+	// SegmentDuration is target duration of HLS segment
+	SegmentDuration time.Duration
+	// Ladder lists HLS renditions from the lowest one, renditions higher than source are skipped
+	Ladder []Rendition
+	// Proxy is rendition of mp4 editing proxy
+	Proxy Rendition
+	// Thumbnails configures sprite sheets shown under timeline
+	Thumbnails ThumbnailsConfig
+}
+
+type ThumbnailsConfig struct {
+	// Interval between thumbnails
+	Interval time.Duration
+	// Width of thumbnail, height keeps aspect ratio of video
+	Width int
+	// Columns and Rows of thumbnails in one sprite sheet
+	Columns int
+	Rows    int
+}
+
+func DefaultPreviewConfig() PreviewConfig {
+	return PreviewConfig{
+		SegmentDuration: 6 * time.Second,
+		Ladder: []Rendition{
+			{Name: "360p", Height: 360, VideoBitrate: "800k", AudioBitrate: "96k"},
+			{Name: "720p", Height: 720, VideoBitrate: "2500k", AudioBitrate: "128k"},
+			{Name: "1080p", Height: 1080, VideoBitrate: "5000k", AudioBitrate: "160k"},
+		},
+		Proxy:      Rendition{Name: "proxy", Height: 540, VideoBitrate: "1200k", AudioBitrate: "128k"},
+		Thumbnails: ThumbnailsConfig{Interval: 10 * time.Second, Width: 160, Columns: 10, Rows: 10},
+	}
+}
+
+// scale returns filter which scales video down to height keeping aspect ratio, smaller video is not upscaled
+func scale(height int) string {
+	return fmt.Sprintf("scale=-2:'trunc(min(%d,ih)/2)*2'", height)
+}
+
+// ladder returns renditions which are not higher than source, the lowest one is always kept.
+// Zero height of source keeps all renditions.
+func (s *MediaServiceImpl) ladder(height int) []Rendition {
+	ladder := make([]Rendition, 0, len(s.preview.Ladder))
+	for _, rendition := range s.preview.Ladder {
+		if height > 0 && rendition.Height > height && len(ladder) > 0 {
+			continue
+		}
+		ladder = append(ladder, rendition)
+	}
+
+	return ladder
+}
+
+// MakeProxy transcodes video to low-bitrate mp4 which editor plays instead of original, name of proxy is returned
+func (s *MediaServiceImpl) MakeProxy(ctx context.Context, name string) (string, error) {
+	ws, err := s.newWorkspace()
+	if err != nil {
+		return "", err
+	}
+	defer ws.close()
+
+	input, err := ws.fetch(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	proxy := s.preview.Proxy
+	proxyName := uuid.New().String() + ".mp4"
+	_, err = exec.CommandContext(ctx, "ffmpeg", "-i", input, "-map", "0:v:0", "-map", "0:a:0",
+		"-vf", scale(proxy.Height), "-c:v", "libx264", "-preset", "veryfast", "-b:v", proxy.VideoBitrate,
+		"-pix_fmt", "yuv420p", "-c:a", "aac", "-b:a", proxy.AudioBitrate, "-movflags", "+faststart",
+		ws.path(proxyName)).Output()
+	if err != nil {
+		s.logger.Error("error while making proxy: ", err)
+		return "", err
+	}
+
+	if err = ws.push(ctx, proxyName); err != nil {
+		return "", err
+	}
+
+	return proxyName, nil
+}
+
+// MakeHLS segments media to HLS ladder and returns name of its master playlist.
+// Audio track is taken from audioName when it is set, otherwise from video,
+// empty videoName makes audio-only playlist. Height of source video limits ladder.
+func (s *MediaServiceImpl) MakeHLS(ctx context.Context, videoName string, audioName string, height int) (string, error) {
+	ws, err := s.newWorkspace()
+	if err != nil {
+		return "", err
+	}
+	defer ws.close()
+
+	var args []string
+	for _, name := range []string{videoName, audioName} {
+		if name == "" {
+			continue
+		}
+
+		input, err := ws.fetch(ctx, name)
+		if err != nil {
+			return "", err
+		}
+		args = append(args, "-i", input)
+	}
+	// the last input is audio one, it is the same as video one when audio is not given
+	audio := fmt.Sprintf("%d:a:0", len(args)/2-1)
+
+	var streamMap []string
+	if videoName == "" {
+		ladder := s.preview.Ladder
+		args = append(args, "-map", audio, "-c:a", "aac", "-b:a", ladder[len(ladder)-1].AudioBitrate)
+		streamMap = append(streamMap, "a:0,name:audio")
+	} else {
+		ladder := s.ladder(height)
+		split := fmt.Sprintf("[0:v:0]split=%d", len(ladder))
+		filters := make([]string, 0, len(ladder)+1)
+		for i, rendition := range ladder {
+			split += fmt.Sprintf("[v%d]", i)
+			filters = append(filters, fmt.Sprintf("[v%d]%s[v%dout]", i, scale(rendition.Height), i))
+
+			args = append(args, "-map", fmt.Sprintf("[v%dout]", i), "-map", audio,
+				fmt.Sprintf("-c:v:%d", i), "libx264", fmt.Sprintf("-b:v:%d", i), rendition.VideoBitrate,
+				fmt.Sprintf("-c:a:%d", i), "aac", fmt.Sprintf("-b:a:%d", i), rendition.AudioBitrate)
+			streamMap = append(streamMap, fmt.Sprintf("v:%d,a:%d,name:%s", i, i, rendition.Name))
+		}
+
+		// key frames are forced at segment boundaries, so renditions can be switched between segments
+		args = append(args, "-filter_complex", strings.Join(append([]string{split}, filters...), ";"),
+			"-preset", "veryfast", "-pix_fmt", "yuv420p",
+			"-force_key_frames", fmt.Sprintf("expr:gte(t,n_forced*%g)", s.preview.SegmentDuration.Seconds()))
+	}
+
+	dirName := uuid.New().String()
+	dir := filepath.Join(ws.dir, dirName)
+	args = append(args, "-f", "hls", "-hls_time", strconv.FormatFloat(s.preview.SegmentDuration.Seconds(), 'f', -1, 64),
+		"-hls_playlist_type", "vod", "-hls_segment_filename", filepath.Join(dir, "%v", "segment_%05d.ts"),
+		"-master_pl_name", model.HLSMasterPlaylist, "-var_stream_map", strings.Join(streamMap, " "),
+		filepath.Join(dir, "%v", "index.m3u8"))
+
+	if _, err = exec.CommandContext(ctx, "ffmpeg", args...).Output(); err != nil {
+		s.logger.Error("error while making HLS: ", err)
+		return "", err
+	}
+
+	prefix := model.HLSDir + dirName
+	if err = s.pushDir(ctx, dir, prefix); err != nil {
+		return "", err
+	}
+
+	return prefix + "/" + model.HLSMasterPlaylist, nil
+}
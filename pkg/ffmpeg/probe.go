@@ -0,0 +1,109 @@
+package ffmpeg
+
+import (
+	"context"
+	"encoding/json"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"tiflo/model"
+)
+
+type probeOutput struct {
+	Format struct {
+		FormatName string `json:"format_name"`
+		Duration   string `json:"duration"`
+		Size       string `json:"size"`
+		BitRate    string `json:"bit_rate"`
+	} `json:"format"`
+	Streams []struct {
+		CodecType    string `json:"codec_type"`
+		CodecName    string `json:"codec_name"`
+		Width        int    `json:"width"`
+		Height       int    `json:"height"`
+		AvgFrameRate string `json:"avg_frame_rate"`
+		SampleRate   string `json:"sample_rate"`
+		Channels     int    `json:"channels"`
+	} `json:"streams"`
+}
+
+// parseRate parses frame rate of ffprobe given as fraction, e.g. 30000/1001
+func parseRate(rate string) float64 {
+	num, den, found := strings.Cut(rate, "/")
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0
+	}
+	if !found {
+		return n
+	}
+
+	d, err := strconv.ParseFloat(den, 64)
+	if err != nil || d == 0 {
+		return 0
+	}
+
+	return n / d
+}
+
+// Probe reads container, streams and duration of file with ffprobe.
+// File which ffprobe does not recognise gives model.InvalidMedia.
+func (s *MediaServiceImpl) Probe(ctx context.Context, name string) (model.MediaProbe, error) {
+	ws, err := s.newWorkspace()
+	if err != nil {
+		return model.MediaProbe{}, err
+	}
+	defer ws.close()
+
+	input, err := ws.fetch(ctx, name)
+	if err != nil {
+		return model.MediaProbe{}, err
+	}
+
+	out, err := exec.CommandContext(ctx, "ffprobe", "-v", "error", "-print_format", "json",
+		"-show_format", "-show_streams", input).Output()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			s.logger.Info("ffprobe rejected ", name, ": ", err)
+			return model.MediaProbe{}, model.InvalidMedia
+		}
+		s.logger.Error("error while probing: ", err)
+		return model.MediaProbe{}, err
+	}
+
+	var output probeOutput
+	if err = json.Unmarshal(out, &output); err != nil {
+		s.logger.Error("error while parsing probe: ", err)
+		return model.MediaProbe{}, err
+	}
+
+	duration, _ := strconv.ParseFloat(output.Format.Duration, 64)
+	probe := model.MediaProbe{
+		Container: output.Format.FormatName,
+		Duration:  int64(duration * 10),
+	}
+	probe.Size, _ = strconv.ParseInt(output.Format.Size, 10, 64)
+	probe.BitRate, _ = strconv.ParseInt(output.Format.BitRate, 10, 64)
+
+	for _, stream := range output.Streams {
+		switch {
+		case stream.CodecType == "video" && probe.Video == nil:
+			probe.Video = &model.VideoStream{
+				Codec:     stream.CodecName,
+				Width:     stream.Width,
+				Height:    stream.Height,
+				FrameRate: parseRate(stream.AvgFrameRate),
+			}
+		case stream.CodecType == "audio" && probe.Audio == nil:
+			sampleRate, _ := strconv.Atoi(stream.SampleRate)
+			probe.Audio = &model.AudioStream{
+				Codec:      stream.CodecName,
+				SampleRate: sampleRate,
+				Channels:   stream.Channels,
+			}
+		}
+	}
+
+	return probe, nil
+}
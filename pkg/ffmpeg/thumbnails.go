@@ -0,0 +1,98 @@
+package ffmpeg
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"tiflo/model"
+
+	"github.com/google/uuid"
+)
+
+// vttTime formats time as WebVTT timestamp hh:mm:ss.ttt
+func vttTime(t time.Duration) string {
+	ms := t.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// thumbnailsIndex returns WebVTT index where every cue refers to region of sprite sheet with thumbnail of its time
+func thumbnailsIndex(config ThumbnailsConfig, count int, height int, duration time.Duration, sheetName func(int) string) string {
+	perSheet := config.Columns * config.Rows
+
+	var index strings.Builder
+	index.WriteString("WEBVTT\n")
+	for i := 0; i < count; i++ {
+		start := time.Duration(i) * config.Interval
+		end := start + config.Interval
+		if end > duration {
+			end = duration
+		}
+
+		cell := i % perSheet
+		fmt.Fprintf(&index, "\n%s --> %s\n%s#xywh=%d,%d,%d,%d\n", vttTime(start), vttTime(end), sheetName(i/perSheet),
+			cell%config.Columns*config.Width, cell/config.Columns*height, config.Width, height)
+	}
+
+	return index.String()
+}
+
+// MakeThumbnails makes sprite sheets of video thumbnails taken every configured interval and WebVTT index of them,
+// name of index is returned. Size and duration of video are taken from probe.
+func (s *MediaServiceImpl) MakeThumbnails(ctx context.Context, videoName string, probe model.MediaProbe) (string, error) {
+	config := s.preview.Thumbnails
+	if probe.Video == nil || probe.Video.Width == 0 || probe.Duration <= 0 {
+		return "", fmt.Errorf("size and duration of %s are unknown", videoName)
+	}
+
+	ws, err := s.newWorkspace()
+	if err != nil {
+		return "", err
+	}
+	defer ws.close()
+
+	input, err := ws.fetch(ctx, videoName)
+	if err != nil {
+		return "", err
+	}
+
+	// duration of probe is given in tenths of second
+	duration := time.Duration(probe.Duration) * 100 * time.Millisecond
+	count := int(math.Ceil(float64(duration) / float64(config.Interval)))
+	height := int(math.Round(float64(config.Width*probe.Video.Height)/float64(probe.Video.Width)/2)) * 2
+
+	dirName := uuid.New().String()
+	dir := filepath.Join(ws.dir, dirName)
+	if err = os.Mkdir(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	_, err = exec.CommandContext(ctx, "ffmpeg", "-i", input, "-an",
+		"-vf", fmt.Sprintf("fps=1/%g,scale=%d:%d,tile=%dx%d", config.Interval.Seconds(), config.Width, height,
+			config.Columns, config.Rows),
+		"-q:v", "5", filepath.Join(dir, "sheet_%03d.jpg")).Output()
+	if err != nil {
+		s.logger.Error("error while making thumbnails: ", err)
+		return "", err
+	}
+
+	// ffmpeg numbers images from 1
+	index := thumbnailsIndex(config, count, height, duration, func(sheet int) string {
+		return fmt.Sprintf("sheet_%03d.jpg", sheet+1)
+	})
+	if err = os.WriteFile(filepath.Join(dir, model.ThumbnailsIndex), []byte(index), 0o644); err != nil {
+		return "", err
+	}
+
+	prefix := model.ThumbnailsDir + dirName
+	if err = s.pushDir(ctx, dir, prefix); err != nil {
+		return "", err
+	}
+
+	return prefix + "/" + model.ThumbnailsIndex, nil
+}
@@ -1,13 +1,56 @@
 package ffmpeg
 
-import "os/exec"
+import (
+	"context"
+	"os/exec"
 
-func (s *MediaServiceImpl) GetAudioFromVideo(filename string, extension string) error {
-	_, err := exec.Command("ffmpeg", "-i", s.pathForMedia+filename+extension, s.pathForMedia+filename+".wav").Output()
+	"github.com/google/uuid"
+)
+
+func (s *MediaServiceImpl) GetAudioFromVideo(ctx context.Context, filename string, extension string) error {
+	ws, err := s.newWorkspace()
+	if err != nil {
+		return err
+	}
+	defer ws.close()
+
+	input, err := ws.fetch(ctx, filename+extension)
+	if err != nil {
+		return err
+	}
+
+	_, err = exec.CommandContext(ctx, "ffmpeg", "-i", input, ws.path(filename+".wav")).Output()
 	if err != nil {
 		s.logger.Error(err)
 		return err
 	}
 
-	return nil
+	return ws.push(ctx, filename+".wav")
+}
+
+// GetAudioFromAudio converts audio file to wav which descriptions are mixed with, name of wav is returned
+func (s *MediaServiceImpl) GetAudioFromAudio(ctx context.Context, name string) (string, error) {
+	ws, err := s.newWorkspace()
+	if err != nil {
+		return "", err
+	}
+	defer ws.close()
+
+	input, err := ws.fetch(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	wavName := uuid.New().String() + ".wav"
+	_, err = exec.CommandContext(ctx, "ffmpeg", "-i", input, "-vn", "-acodec", "pcm_s16le", ws.path(wavName)).Output()
+	if err != nil {
+		s.logger.Error(err)
+		return "", err
+	}
+
+	if err = ws.push(ctx, wavName); err != nil {
+		return "", err
+	}
+
+	return wavName, nil
 }
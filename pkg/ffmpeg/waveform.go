@@ -0,0 +1,74 @@
+package ffmpeg
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"tiflo/pkg/waveform"
+
+	"github.com/go-audio/wav"
+)
+
+// Waveform computes peaks of wav file at every zoom, zoom is number of samples per pixel.
+// Channels are mixed down to mono like audiowaveform does by default.
+func (s *MediaServiceImpl) Waveform(ctx context.Context, name string, zooms []int) ([]waveform.Waveform, error) {
+	ws, err := s.newWorkspace()
+	if err != nil {
+		return nil, err
+	}
+	defer ws.close()
+
+	input, err := ws.fetch(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(input)
+	if err != nil {
+		s.logger.Error(err)
+		return nil, err
+	}
+	decoder := wav.NewDecoder(file)
+	decoder.ReadInfo()
+	file.Close()
+	if decoder.Err() != nil || decoder.SampleRate == 0 {
+		return nil, fmt.Errorf("%s is not wav file: %v", name, decoder.Err())
+	}
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-v", "error", "-i", input, "-f", "s16le", "-ac", "1", "-")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err = cmd.Start(); err != nil {
+		s.logger.Error("error while decoding audio: ", err)
+		return nil, err
+	}
+
+	builder := waveform.NewBuilder(int(decoder.SampleRate), zooms)
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := io.ReadFull(stdout, buf)
+		for i := 0; i+1 < n; i += 2 {
+			builder.Add(int16(binary.LittleEndian.Uint16(buf[i:])))
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			cmd.Wait()
+			return nil, err
+		}
+	}
+
+	if err = cmd.Wait(); err != nil {
+		s.logger.Error("error while decoding audio: ", err)
+		return nil, err
+	}
+
+	return builder.Waveforms(), nil
+}
@@ -0,0 +1,87 @@
+package ffmpeg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"tiflo/pkg/storage"
+)
+
+// workspace is temporary directory where ffmpeg reads inputs and writes results
+type workspace struct {
+	dir     string
+	service *MediaServiceImpl
+}
+
+func (s *MediaServiceImpl) newWorkspace() (*workspace, error) {
+	dir, err := os.MkdirTemp(s.tempDir, "media-")
+	if err != nil {
+		s.logger.Error("error while creating workspace: ", err)
+		return nil, err
+	}
+
+	return &workspace{dir: dir, service: s}, nil
+}
+
+// path returns local path of file with given name
+func (w *workspace) path(name string) string {
+	return filepath.Join(w.dir, filepath.Base(name))
+}
+
+// fetch makes object available for ffmpeg and returns its local path,
+// files of local storage are read in place
+func (w *workspace) fetch(ctx context.Context, name string) (string, error) {
+	if local, ok := w.service.storage.(*storage.LocalStorage); ok {
+		return filepath.Join(local.Dir(), name), nil
+	}
+
+	path := w.path(name)
+	if err := storage.GetFile(ctx, w.service.storage, name, path); err != nil {
+		w.service.logger.Error("error while fetching ", name, ": ", err)
+		return "", err
+	}
+
+	return path, nil
+}
+
+// push uploads result of ffmpeg to storage under the same name
+func (w *workspace) push(ctx context.Context, name string) error {
+	if err := storage.PutFile(ctx, w.service.storage, name, w.path(name)); err != nil {
+		w.service.logger.Error("error while pushing ", name, ": ", err)
+		return err
+	}
+
+	return nil
+}
+
+// pushDir uploads every file of local dir to storage under prefix keeping their layout, because files of
+// previews refer to each other by relative paths. Files already pushed are deleted on failure.
+func (s *MediaServiceImpl) pushDir(ctx context.Context, dir string, prefix string) error {
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		return storage.PutFile(ctx, s.storage, prefix+"/"+filepath.ToSlash(rel), path)
+	})
+	if err != nil {
+		s.logger.Error("error while pushing ", prefix, ": ", err)
+		if err := storage.DeleteDir(ctx, s.storage, prefix); err != nil {
+			s.logger.Error(err)
+		}
+	}
+
+	return err
+}
+
+func (w *workspace) close() {
+	if err := os.RemoveAll(w.dir); err != nil {
+		w.service.logger.Error("error while removing workspace: ", err)
+	}
+}
@@ -2,38 +2,60 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 
+	"tiflo/model"
 	"tiflo/pkg/grpc/generated"
 	pb "tiflo/pkg/grpc/generated"
 
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type PythonClient struct {
 	logger           *logrus.Entry
 	voice2textClient generated.AIServiceClient
 	image2textClient generated.ImageCaptioningClient
+	ocrClient        generated.TextRecognitionClient
+	speechClient     generated.SpeechRecognitionClient
 }
 
+// ServiceNotSet is returned by methods of optional services when their address is not configured
+var ServiceNotSet = errors.New("ServiceNotSet")
+
 type AI interface {
-	VoiceTheText(context context.Context, text string) (string, error)
+	// VoiceTheText voices plain text or SSML document with given voice and returns name of wav file
+	VoiceTheText(context context.Context, text string, settings model.VoiceSettings) (string, error)
 	ImageToText(context context.Context, path string) (string, error)
+	// FrameContextToText captions several frames together, so motion and neighbouring descriptions are taken into account
+	FrameContextToText(context context.Context, frameContext model.FrameContext) (string, error)
+	// RecognizeText returns blocks of on-screen text of image, nothing is returned when OCR service is not set
+	RecognizeText(context context.Context, path string) ([]model.TextBlock, error)
+	// Transcribe recognises dialogue of audio file, empty language is detected by service
+	Transcribe(context context.Context, path string, language string) ([]model.TranscriptSegment, error)
 }
 
-func NewPythonClient(logger *logrus.Logger, voice2textClient generated.AIServiceClient, image2textClient generated.ImageCaptioningClient) *PythonClient {
+func NewPythonClient(logger *logrus.Logger, voice2textClient generated.AIServiceClient, image2textClient generated.ImageCaptioningClient,
+	ocrClient generated.TextRecognitionClient, speechClient generated.SpeechRecognitionClient) *PythonClient {
 	return &PythonClient{
 		logger:           logger.WithField("component", "python_client"),
 		voice2textClient: voice2textClient,
 		image2textClient: image2textClient,
+		ocrClient:        ocrClient,
+		speechClient:     speechClient,
 	}
 }
 
-func (p *PythonClient) VoiceTheText(context context.Context, text string) (string, error) {
+func (p *PythonClient) VoiceTheText(context context.Context, text string, settings model.VoiceSettings) (string, error) {
 	//p.logger.Info("text: ", text)
 	fmt.Println("text: ", text)
 	request := pb.TextToVoice{
-		Text: text,
+		Text:  text,
+		Ssml:  settings.SSML,
+		Voice: settings.Voice,
 	}
 	resp, err := p.voice2textClient.VoiceTheText(context, &request)
 	if err != nil {
@@ -61,3 +83,89 @@ func (p *PythonClient) ImageToText(context context.Context, path string) (string
 	p.logger.Info("answer", resp.Text)
 	return resp.Text, nil
 }
+
+func (p *PythonClient) FrameContextToText(context context.Context, frameContext model.FrameContext) (string, error) {
+	request := pb.FrameContext{
+		Frames:       make([]*pb.Frame, 0, len(frameContext.Frames)),
+		PreviousText: frameContext.PreviousText,
+		NextText:     frameContext.NextText,
+	}
+	for _, frame := range frameContext.Frames {
+		request.Frames = append(request.Frames, &pb.Frame{ImagePath: frame.Path, Offset: frame.Offset})
+	}
+
+	resp, err := p.image2textClient.FrameContextCaption(context, &request)
+	if status.Code(err) == codes.Unimplemented && len(frameContext.Frames) > 0 {
+		// older image2text service captions only the frame of commented moment
+		p.logger.Warn("frame context caption is not implemented, single frame is captioned")
+		closest := frameContext.Frames[0]
+		for _, frame := range frameContext.Frames {
+			if math.Abs(float64(frame.Offset)) < math.Abs(float64(closest.Offset)) {
+				closest = frame
+			}
+		}
+		return p.ImageToText(context, closest.Path)
+	}
+	if err != nil {
+		p.logger.Error("frame context to text: ", err)
+		return "", err
+	}
+
+	p.logger.Info("answer", resp.Text)
+	return resp.Text, nil
+}
+
+func (p *PythonClient) RecognizeText(context context.Context, path string) ([]model.TextBlock, error) {
+	if p.ocrClient == nil {
+		return nil, nil
+	}
+
+	resp, err := p.ocrClient.RecognizeText(context, &pb.Image{ImagePath: path})
+	if err != nil {
+		p.logger.Error("recognize text: ", err)
+		return nil, err
+	}
+
+	blocks := make([]model.TextBlock, 0, len(resp.Blocks))
+	for _, block := range resp.Blocks {
+		blocks = append(blocks, model.TextBlock{
+			Text:       block.Text,
+			Confidence: block.Confidence,
+			X:          int(block.X),
+			Y:          int(block.Y),
+			Width:      int(block.Width),
+			Height:     int(block.Height),
+		})
+	}
+
+	return blocks, nil
+}
+
+func (p *PythonClient) Transcribe(context context.Context, path string, language string) ([]model.TranscriptSegment, error) {
+	if p.speechClient == nil {
+		return nil, ServiceNotSet
+	}
+
+	resp, err := p.speechClient.Transcribe(context, &pb.SpeechAudio{AudioPath: path, Language: language})
+	if err != nil {
+		p.logger.Error("transcribe: ", err)
+		return nil, err
+	}
+
+	// service gives milliseconds, timeline keeps tenths of a second
+	segments := make([]model.TranscriptSegment, 0, len(resp.Segments))
+	for _, segment := range resp.Segments {
+		if segment.EndMs < segment.StartMs {
+			return nil, fmt.Errorf("segment ends at %d ms before its start at %d ms", segment.EndMs, segment.StartMs)
+		}
+
+		segments = append(segments, model.TranscriptSegment{
+			Start:    segment.StartMs / 100,
+			Duration: (segment.EndMs - segment.StartMs) / 100,
+			Text:     segment.Text,
+			Speaker:  segment.Speaker,
+		})
+	}
+
+	return segments, nil
+}
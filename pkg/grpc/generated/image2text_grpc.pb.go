@@ -23,6 +23,7 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ImageCaptioningClient interface {
 	ImageCaption(ctx context.Context, in *Image, opts ...grpc.CallOption) (*Text, error)
+	FrameContextCaption(ctx context.Context, in *FrameContext, opts ...grpc.CallOption) (*Text, error)
 }
 
 type imageCaptioningClient struct {
@@ -42,11 +43,21 @@ func (c *imageCaptioningClient) ImageCaption(ctx context.Context, in *Image, opt
 	return out, nil
 }
 
+func (c *imageCaptioningClient) FrameContextCaption(ctx context.Context, in *FrameContext, opts ...grpc.CallOption) (*Text, error) {
+	out := new(Text)
+	err := c.cc.Invoke(ctx, "/pb.ImageCaptioning/FrameContextCaption", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ImageCaptioningServer is the server API for ImageCaptioning service.
 // All implementations must embed UnimplementedImageCaptioningServer
 // for forward compatibility
 type ImageCaptioningServer interface {
 	ImageCaption(context.Context, *Image) (*Text, error)
+	FrameContextCaption(context.Context, *FrameContext) (*Text, error)
 	mustEmbedUnimplementedImageCaptioningServer()
 }
 
@@ -57,6 +68,9 @@ type UnimplementedImageCaptioningServer struct {
 func (UnimplementedImageCaptioningServer) ImageCaption(context.Context, *Image) (*Text, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ImageCaption not implemented")
 }
+func (UnimplementedImageCaptioningServer) FrameContextCaption(context.Context, *FrameContext) (*Text, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FrameContextCaption not implemented")
+}
 func (UnimplementedImageCaptioningServer) mustEmbedUnimplementedImageCaptioningServer() {}
 
 // UnsafeImageCaptioningServer may be embedded to opt out of forward compatibility for this service.
@@ -88,6 +102,24 @@ func _ImageCaptioning_ImageCaption_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ImageCaptioning_FrameContextCaption_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FrameContext)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageCaptioningServer).FrameContextCaption(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.ImageCaptioning/FrameContextCaption",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageCaptioningServer).FrameContextCaption(ctx, req.(*FrameContext))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ImageCaptioning_ServiceDesc is the grpc.ServiceDesc for ImageCaptioning service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -99,6 +131,96 @@ var ImageCaptioning_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ImageCaption",
 			Handler:    _ImageCaptioning_ImageCaption_Handler,
 		},
+		{
+			MethodName: "FrameContextCaption",
+			Handler:    _ImageCaptioning_FrameContextCaption_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "image2text.proto",
+}
+
+// TextRecognitionClient is the client API for TextRecognition service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TextRecognitionClient interface {
+	RecognizeText(ctx context.Context, in *Image, opts ...grpc.CallOption) (*TextBlocks, error)
+}
+
+type textRecognitionClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTextRecognitionClient(cc grpc.ClientConnInterface) TextRecognitionClient {
+	return &textRecognitionClient{cc}
+}
+
+func (c *textRecognitionClient) RecognizeText(ctx context.Context, in *Image, opts ...grpc.CallOption) (*TextBlocks, error) {
+	out := new(TextBlocks)
+	err := c.cc.Invoke(ctx, "/pb.TextRecognition/RecognizeText", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TextRecognitionServer is the server API for TextRecognition service.
+// All implementations must embed UnimplementedTextRecognitionServer
+// for forward compatibility
+type TextRecognitionServer interface {
+	RecognizeText(context.Context, *Image) (*TextBlocks, error)
+	mustEmbedUnimplementedTextRecognitionServer()
+}
+
+// UnimplementedTextRecognitionServer must be embedded to have forward compatible implementations.
+type UnimplementedTextRecognitionServer struct {
+}
+
+func (UnimplementedTextRecognitionServer) RecognizeText(context.Context, *Image) (*TextBlocks, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecognizeText not implemented")
+}
+func (UnimplementedTextRecognitionServer) mustEmbedUnimplementedTextRecognitionServer() {}
+
+// UnsafeTextRecognitionServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TextRecognitionServer will
+// result in compilation errors.
+type UnsafeTextRecognitionServer interface {
+	mustEmbedUnimplementedTextRecognitionServer()
+}
+
+func RegisterTextRecognitionServer(s grpc.ServiceRegistrar, srv TextRecognitionServer) {
+	s.RegisterService(&TextRecognition_ServiceDesc, srv)
+}
+
+func _TextRecognition_RecognizeText_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Image)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TextRecognitionServer).RecognizeText(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.TextRecognition/RecognizeText",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TextRecognitionServer).RecognizeText(ctx, req.(*Image))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TextRecognition_ServiceDesc is the grpc.ServiceDesc for TextRecognition service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TextRecognition_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.TextRecognition",
+	HandlerType: (*TextRecognitionServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RecognizeText",
+			Handler:    _TextRecognition_RecognizeText_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "image2text.proto",
@@ -0,0 +1,105 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.12.4
+// source: speech2text.proto
+
+package generated
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// SpeechRecognitionClient is the client API for SpeechRecognition service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SpeechRecognitionClient interface {
+	Transcribe(ctx context.Context, in *SpeechAudio, opts ...grpc.CallOption) (*Transcript, error)
+}
+
+type speechRecognitionClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSpeechRecognitionClient(cc grpc.ClientConnInterface) SpeechRecognitionClient {
+	return &speechRecognitionClient{cc}
+}
+
+func (c *speechRecognitionClient) Transcribe(ctx context.Context, in *SpeechAudio, opts ...grpc.CallOption) (*Transcript, error) {
+	out := new(Transcript)
+	err := c.cc.Invoke(ctx, "/pb.SpeechRecognition/Transcribe", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SpeechRecognitionServer is the server API for SpeechRecognition service.
+// All implementations must embed UnimplementedSpeechRecognitionServer
+// for forward compatibility
+type SpeechRecognitionServer interface {
+	Transcribe(context.Context, *SpeechAudio) (*Transcript, error)
+	mustEmbedUnimplementedSpeechRecognitionServer()
+}
+
+// UnimplementedSpeechRecognitionServer must be embedded to have forward compatible implementations.
+type UnimplementedSpeechRecognitionServer struct {
+}
+
+func (UnimplementedSpeechRecognitionServer) Transcribe(context.Context, *SpeechAudio) (*Transcript, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Transcribe not implemented")
+}
+func (UnimplementedSpeechRecognitionServer) mustEmbedUnimplementedSpeechRecognitionServer() {}
+
+// UnsafeSpeechRecognitionServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SpeechRecognitionServer will
+// result in compilation errors.
+type UnsafeSpeechRecognitionServer interface {
+	mustEmbedUnimplementedSpeechRecognitionServer()
+}
+
+func RegisterSpeechRecognitionServer(s grpc.ServiceRegistrar, srv SpeechRecognitionServer) {
+	s.RegisterService(&SpeechRecognition_ServiceDesc, srv)
+}
+
+func _SpeechRecognition_Transcribe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SpeechAudio)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SpeechRecognitionServer).Transcribe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.SpeechRecognition/Transcribe",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SpeechRecognitionServer).Transcribe(ctx, req.(*SpeechAudio))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SpeechRecognition_ServiceDesc is the grpc.ServiceDesc for SpeechRecognition service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SpeechRecognition_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.SpeechRecognition",
+	HandlerType: (*SpeechRecognitionServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Transcribe",
+			Handler:    _SpeechRecognition_Transcribe_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "speech2text.proto",
+}
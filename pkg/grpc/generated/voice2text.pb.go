@@ -26,6 +26,10 @@ type TextToVoice struct {
 	unknownFields protoimpl.UnknownFields
 
 	Text string `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	// text is SSML document instead of plain text
+	Ssml bool `protobuf:"varint,2,opt,name=ssml,proto3" json:"ssml,omitempty"`
+	// name of TTS voice, empty means default voice
+	Voice string `protobuf:"bytes,3,opt,name=voice,proto3" json:"voice,omitempty"`
 }
 
 func (x *TextToVoice) Reset() {
@@ -67,6 +71,20 @@ func (x *TextToVoice) GetText() string {
 	return ""
 }
 
+func (x *TextToVoice) GetSsml() bool {
+	if x != nil {
+		return x.Ssml
+	}
+	return false
+}
+
+func (x *TextToVoice) GetVoice() string {
+	if x != nil {
+		return x.Voice
+	}
+	return ""
+}
+
 type Audio struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -118,16 +136,19 @@ var File_voice2text_proto protoreflect.FileDescriptor
 
 var file_voice2text_proto_rawDesc = []byte{
 	0x0a, 0x10, 0x76, 0x6f, 0x69, 0x63, 0x65, 0x32, 0x74, 0x65, 0x78, 0x74, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x12, 0x02, 0x70, 0x62, 0x22, 0x21, 0x0a, 0x0b, 0x54, 0x65, 0x78, 0x74, 0x54, 0x6f,
+	0x74, 0x6f, 0x12, 0x02, 0x70, 0x62, 0x22, 0x4b, 0x0a, 0x0b, 0x54, 0x65, 0x78, 0x74, 0x54, 0x6f,
 	0x56, 0x6f, 0x69, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x78, 0x74, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x22, 0x1d, 0x0a, 0x05, 0x41, 0x75, 0x64,
-	0x69, 0x6f, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x05, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x32, 0x37, 0x0a, 0x09, 0x41, 0x49, 0x53, 0x65,
-	0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x2a, 0x0a, 0x0c, 0x56, 0x6f, 0x69, 0x63, 0x65, 0x54, 0x68,
-	0x65, 0x54, 0x65, 0x78, 0x74, 0x12, 0x0f, 0x2e, 0x70, 0x62, 0x2e, 0x54, 0x65, 0x78, 0x74, 0x54,
-	0x6f, 0x56, 0x6f, 0x69, 0x63, 0x65, 0x1a, 0x09, 0x2e, 0x70, 0x62, 0x2e, 0x41, 0x75, 0x64, 0x69,
-	0x6f, 0x42, 0x14, 0x5a, 0x12, 0x70, 0x6b, 0x67, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x67, 0x65,
-	0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x73, 0x6d,
+	0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x73, 0x73, 0x6d, 0x6c, 0x12, 0x14, 0x0a,
+	0x05, 0x76, 0x6f, 0x69, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x6f,
+	0x69, 0x63, 0x65, 0x22, 0x1d, 0x0a, 0x05, 0x41, 0x75, 0x64, 0x69, 0x6f, 0x12, 0x14, 0x0a, 0x05,
+	0x61, 0x75, 0x64, 0x69, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x61, 0x75, 0x64,
+	0x69, 0x6f, 0x32, 0x37, 0x0a, 0x09, 0x41, 0x49, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12,
+	0x2a, 0x0a, 0x0c, 0x56, 0x6f, 0x69, 0x63, 0x65, 0x54, 0x68, 0x65, 0x54, 0x65, 0x78, 0x74, 0x12,
+	0x0f, 0x2e, 0x70, 0x62, 0x2e, 0x54, 0x65, 0x78, 0x74, 0x54, 0x6f, 0x56, 0x6f, 0x69, 0x63, 0x65,
+	0x1a, 0x09, 0x2e, 0x70, 0x62, 0x2e, 0x41, 0x75, 0x64, 0x69, 0x6f, 0x42, 0x14, 0x5a, 0x12, 0x70,
+	0x6b, 0x67, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65,
+	0x64, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -0,0 +1,140 @@
+package lexicon
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"tiflo/model"
+)
+
+// Merge returns one entry per term, project entries take precedence over user ones
+func Merge(entries []model.LexiconEntry) []model.LexiconEntry {
+	byTerm := map[string]model.LexiconEntry{}
+	for _, entry := range entries {
+		key := strings.ToLower(entry.Term)
+		if existing, ok := byTerm[key]; ok && existing.ProjectId != nil && entry.ProjectId == nil {
+			continue
+		}
+		byTerm[key] = entry
+	}
+
+	merged := make([]model.LexiconEntry, 0, len(byTerm))
+	for _, entry := range byTerm {
+		merged = append(merged, entry)
+	}
+
+	// longer terms first, so "ООО Ромашка" is matched before "ООО"
+	sort.Slice(merged, func(i, j int) bool {
+		if utf8.RuneCountInString(merged[i].Term) != utf8.RuneCountInString(merged[j].Term) {
+			return utf8.RuneCountInString(merged[i].Term) > utf8.RuneCountInString(merged[j].Term)
+		}
+		return merged[i].Term < merged[j].Term
+	})
+
+	return merged
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// matchTerm returns length in bytes of entry term which starts at text[i:] as a whole word, or 0
+func matchTerm(text string, i int, term string) int {
+	termLen := utf8.RuneCountInString(term)
+	j := i
+	for n := 0; n < termLen; n++ {
+		if j >= len(text) {
+			return 0
+		}
+		_, size := utf8.DecodeRuneInString(text[j:])
+		j += size
+	}
+
+	if !strings.EqualFold(text[i:j], term) {
+		return 0
+	}
+
+	if next, _ := utf8.DecodeRuneInString(text[j:]); j < len(text) && isWordRune(next) && isWordRune([]rune(term)[termLen-1]) {
+		return 0
+	}
+
+	return j - i
+}
+
+func render(entry model.LexiconEntry, original string, ssml bool) string {
+	if !ssml {
+		if entry.Alias != "" {
+			return entry.Alias
+		}
+		return original
+	}
+
+	switch {
+	case entry.Phoneme != "":
+		alphabet := entry.Alphabet
+		if alphabet == "" {
+			alphabet = model.AlphabetIPA
+		}
+		return `<phoneme alphabet="` + html.EscapeString(alphabet) + `" ph="` + html.EscapeString(entry.Phoneme) + `">` +
+			html.EscapeString(original) + `</phoneme>`
+	case entry.Alias != "":
+		return `<sub alias="` + html.EscapeString(entry.Alias) + `">` + html.EscapeString(original) + `</sub>`
+	default:
+		return html.EscapeString(original)
+	}
+}
+
+// Prepare applies lexicon to description text. In plain text mode terms are replaced with aliases,
+// in SSML mode text is escaped and terms are wrapped into <sub> and <phoneme> tags.
+func Prepare(entries []model.LexiconEntry, text string, ssml bool) string {
+	entries = Merge(entries)
+
+	var result strings.Builder
+	plain := 0
+	flush := func(end int) {
+		if ssml {
+			result.WriteString(html.EscapeString(text[plain:end]))
+		} else {
+			result.WriteString(text[plain:end])
+		}
+	}
+
+	prevIsWord := false
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		matched := 0
+		if !prevIsWord || !isWordRune(r) {
+			for _, entry := range entries {
+				if entry.Term == "" {
+					continue
+				}
+				if matched = matchTerm(text, i, entry.Term); matched > 0 {
+					flush(i)
+					result.WriteString(render(entry, text[i:i+matched], ssml))
+					plain = i + matched
+					break
+				}
+			}
+		}
+
+		if matched > 0 {
+			last, _ := utf8.DecodeLastRuneInString(text[:i+matched])
+			prevIsWord = isWordRune(last)
+			i += matched
+			continue
+		}
+
+		prevIsWord = isWordRune(r)
+		i += size
+	}
+	flush(len(text))
+
+	if ssml {
+		return "<speak>" + result.String() + "</speak>"
+	}
+
+	return result.String()
+}
@@ -0,0 +1,179 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"tiflo/model"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Linter checks descriptions against style guide
+type Linter interface {
+	LintPart(config model.LintConfig, part model.AudioPart) []model.LintWarning
+	LintProject(config model.LintConfig, project model.Project) []model.LintWarning
+}
+
+type LinterImpl struct {
+	logger *logrus.Entry
+}
+
+func NewLinter(logger *logrus.Logger) Linter {
+	return &LinterImpl{logger: logger.WithField("component", "linter")}
+}
+
+// DefaultConfig is used when neither organisation nor project style guide is set
+func DefaultConfig() model.LintConfig {
+	return model.LintConfig{
+		MaxWordsPerSecond: 3,
+		MaxLength:         300,
+		BannedPhrases: []string{
+			"image of", "picture of", "we see", "we can see",
+			"на изображении", "на картинке", "мы видим", "можно увидеть",
+		},
+		SubjectiveWords: []string{
+			"beautiful", "ugly", "gorgeous", "amazing", "awful", "cute",
+			"красив", "уродлив", "прекрасн", "ужасн", "милый", "мила", "симпатичн",
+		},
+		RepeatedWords: 3,
+	}
+}
+
+// words splits text into lower case words
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func isDisabled(config model.LintConfig, rule string) bool {
+	for _, disabled := range config.DisabledRules {
+		if disabled == rule {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (l *LinterImpl) LintPart(config model.LintConfig, part model.AudioPart) []model.LintWarning {
+	warnings := make([]model.LintWarning, 0)
+	if part.Text == "" {
+		return warnings
+	}
+
+	partWords := words(part.Text)
+
+	// duration is kept in tenths of second
+	if !isDisabled(config, model.LintRuleWordsPerSecond) && config.MaxWordsPerSecond > 0 && part.Duration > 0 {
+		wordsPerSecond := float64(len(partWords)) / (float64(part.Duration) / 10)
+		if wordsPerSecond > config.MaxWordsPerSecond {
+			warnings = append(warnings, model.LintWarning{
+				PartId:  part.PartId,
+				Rule:    model.LintRuleWordsPerSecond,
+				Message: fmt.Sprintf("слишком быстрая речь: %.1f слов в секунду, допустимо %.1f", wordsPerSecond, config.MaxWordsPerSecond),
+			})
+		}
+	}
+
+	if !isDisabled(config, model.LintRuleMaxLength) && config.MaxLength > 0 {
+		if length := len([]rune(part.Text)); length > config.MaxLength {
+			warnings = append(warnings, model.LintWarning{
+				PartId:  part.PartId,
+				Rule:    model.LintRuleMaxLength,
+				Message: fmt.Sprintf("слишком длинное описание: %d символов, допустимо %d", length, config.MaxLength),
+			})
+		}
+	}
+
+	if !isDisabled(config, model.LintRuleBannedPhrase) {
+		joined := " " + strings.Join(partWords, " ") + " "
+		for _, phrase := range config.BannedPhrases {
+			phraseWords := words(phrase)
+			if len(phraseWords) == 0 {
+				continue
+			}
+
+			if strings.Contains(joined, " "+strings.Join(phraseWords, " ")+" ") {
+				warnings = append(warnings, model.LintWarning{
+					PartId:   part.PartId,
+					Rule:     model.LintRuleBannedPhrase,
+					Message:  "нежелательная фраза",
+					Fragment: phrase,
+				})
+			}
+		}
+	}
+
+	if !isDisabled(config, model.LintRuleSubjective) {
+		for _, word := range partWords {
+			for _, stem := range config.SubjectiveWords {
+				if stem != "" && strings.HasPrefix(word, strings.ToLower(stem)) {
+					warnings = append(warnings, model.LintWarning{
+						PartId:   part.PartId,
+						Rule:     model.LintRuleSubjective,
+						Message:  "субъективная оценка",
+						Fragment: word,
+					})
+					break
+				}
+			}
+		}
+	}
+
+	return warnings
+}
+
+// LintProject checks every description of project and repeated wording in neighbouring descriptions
+func (l *LinterImpl) LintProject(config model.LintConfig, project model.Project) []model.LintWarning {
+	parts := make([]model.AudioPart, 0, len(project.AudioParts))
+	for _, part := range project.AudioParts {
+		if part.Text != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	sort.SliceStable(parts, func(i, j int) bool {
+		return parts[i].Start < parts[j].Start
+	})
+
+	warnings := make([]model.LintWarning, 0)
+	for i, part := range parts {
+		warnings = append(warnings, l.LintPart(config, part)...)
+
+		if i == 0 || isDisabled(config, model.LintRuleRepetition) || config.RepeatedWords <= 0 {
+			continue
+		}
+
+		if repeated := repeatedSequence(words(parts[i-1].Text), words(part.Text), config.RepeatedWords); repeated != "" {
+			warnings = append(warnings, model.LintWarning{
+				PartId:   part.PartId,
+				Rule:     model.LintRuleRepetition,
+				Message:  "повтор формулировки из предыдущего описания",
+				Fragment: repeated,
+			})
+		}
+	}
+
+	return warnings
+}
+
+// repeatedSequence returns first sequence of n words of current text which is present in previous one
+func repeatedSequence(previous []string, current []string, n int) string {
+	sequences := map[string]bool{}
+	for i := 0; i+n <= len(previous); i++ {
+		sequences[strings.Join(previous[i:i+n], " ")] = true
+	}
+
+	for i := 0; i+n <= len(current); i++ {
+		sequence := strings.Join(current[i:i+n], " ")
+		if sequences[sequence] {
+			return sequence
+		}
+	}
+
+	return ""
+}
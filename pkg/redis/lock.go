@@ -0,0 +1,129 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	lockPrefix  = "lock."
+	fencePrefix = "fence."
+)
+
+var (
+	ErrLockBusy = errors.New("lock is held by another holder")
+	ErrLockLost = errors.New("lock has expired or was taken by another holder")
+)
+
+// Lock is a lease on resource. Token is a fencing token, it grows with every successful acquire of resource,
+// so storage can reject writes of holder whose lease has already expired.
+type Lock struct {
+	Resource string
+	Holder   string
+	Token    int64
+	TTL      time.Duration
+}
+
+func getLockKey(resource string) string {
+	return servicePrefix + lockPrefix + resource
+}
+
+func getFenceKey(resource string) string {
+	return servicePrefix + fencePrefix + resource
+}
+
+// KEYS[1] - lock key, KEYS[2] - fencing counter key, ARGV[1] - holder, ARGV[2] - ttl in ms
+var acquireScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return {0, redis.call('HGET', KEYS[1], 'holder'), tonumber(redis.call('HGET', KEYS[1], 'token')), redis.call('PTTL', KEYS[1])}
+end
+local token = redis.call('INCR', KEYS[2])
+redis.call('HSET', KEYS[1], 'holder', ARGV[1], 'token', token)
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return {1, ARGV[1], token, tonumber(ARGV[2])}
+`)
+
+// KEYS[1] - lock key, ARGV[1] - fencing token, ARGV[2] - ttl in ms
+var extendScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'token') == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// KEYS[1] - lock key, ARGV[1] - fencing token
+var releaseScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'token') == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// AcquireLock takes lease on resource for ttl. If resource is already locked, ErrLockBusy is returned
+// together with current lock, its TTL is the time left until lease expires.
+func (c *RedisClient) AcquireLock(ctx context.Context, resource string, holder string, ttl time.Duration) (Lock, error) {
+	res, err := acquireScript.Run(ctx, c.client, []string{getLockKey(resource), getFenceKey(resource)},
+		holder, ttl.Milliseconds()).Slice()
+	if err != nil {
+		c.logger.Error("acquire lock: ", err)
+		return Lock{}, err
+	}
+
+	if len(res) != 4 {
+		return Lock{}, errors.New("unexpected acquire lock result")
+	}
+
+	acquired, _ := res[0].(int64)
+	lockHolder, _ := res[1].(string)
+	token, _ := res[2].(int64)
+	ttlMs, _ := res[3].(int64)
+
+	lock := Lock{
+		Resource: resource,
+		Holder:   lockHolder,
+		Token:    token,
+		TTL:      time.Duration(ttlMs) * time.Millisecond,
+	}
+
+	if acquired != 1 {
+		return lock, ErrLockBusy
+	}
+
+	return lock, nil
+}
+
+// ExtendLock prolongs lease if it is still held with the same fencing token
+func (c *RedisClient) ExtendLock(ctx context.Context, lock Lock, ttl time.Duration) error {
+	res, err := extendScript.Run(ctx, c.client, []string{getLockKey(lock.Resource)},
+		strconv.FormatInt(lock.Token, 10), ttl.Milliseconds()).Int64()
+	if err != nil {
+		c.logger.Error("extend lock: ", err)
+		return err
+	}
+
+	if res == 0 {
+		return ErrLockLost
+	}
+
+	return nil
+}
+
+// ReleaseLock removes lease only if it is still held with the same fencing token
+func (c *RedisClient) ReleaseLock(ctx context.Context, lock Lock) error {
+	res, err := releaseScript.Run(ctx, c.client, []string{getLockKey(lock.Resource)},
+		strconv.FormatInt(lock.Token, 10)).Int64()
+	if err != nil {
+		c.logger.Error("release lock: ", err)
+		return err
+	}
+
+	if res == 0 {
+		return ErrLockLost
+	}
+
+	return nil
+}
@@ -6,7 +6,10 @@ import (
 	"strconv"
 	"time"
 
+	"tiflo/model"
+
 	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
@@ -18,6 +21,7 @@ type RedisConfig struct {
 	User        string
 	DialTimeout time.Duration
 	ReadTimeout time.Duration
+	LockTTL     time.Duration
 }
 
 const (
@@ -35,20 +39,38 @@ type RedisClient struct {
 	client *redis.Client
 }
 
+const defaultLockTTL = 60 * time.Second
+
 type Client interface {
 	CheckJWTInBlacklist(ctx context.Context, jwtStr string) error
 	WriteJWTToBlacklist(ctx context.Context, jwtStr string, jwtTTL time.Duration) error
+
+	AcquireLock(ctx context.Context, resource string, holder string, ttl time.Duration) (Lock, error)
+	ExtendLock(ctx context.Context, lock Lock, ttl time.Duration) error
+	ReleaseLock(ctx context.Context, lock Lock) error
+
+	CreateUpload(ctx context.Context, upload model.Upload, ttl time.Duration) error
+	GetUpload(ctx context.Context, uploadId uuid.UUID) (model.Upload, error)
+	AdvanceUpload(ctx context.Context, uploadId uuid.UUID, offset int64, size int64, part string, ttl time.Duration) (int64, error)
+	DeleteUpload(ctx context.Context, uploadId uuid.UUID) error
 }
 
 func InitRedisConfig(vp *viper.Viper) RedisConfig {
-	return RedisConfig{
+	config := RedisConfig{
 		Host:        vp.GetString(redisHost),
 		Password:    vp.GetString(redisPass),
 		Port:        vp.GetInt(redisPort),
 		User:        vp.GetString(redisUser),
 		DialTimeout: time.Duration(vp.GetInt("redis.dialTimeout")) * time.Second,
 		ReadTimeout: time.Duration(vp.GetInt("redis.readTimeout")) * time.Second,
+		LockTTL:     time.Duration(vp.GetInt("redis.lockTTL")) * time.Second,
 	}
+
+	if config.LockTTL <= 0 {
+		config.LockTTL = defaultLockTTL
+	}
+
+	return config
 }
 
 func NewRedisClient(ctx context.Context, config RedisConfig, logger *logrus.Logger) (*RedisClient, error) {
@@ -0,0 +1,122 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"tiflo/model"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+const (
+	uploadPrefix      = "upload."
+	uploadPartsPrefix = "upload.parts."
+)
+
+func getUploadKey(uploadId uuid.UUID) string {
+	return servicePrefix + uploadPrefix + uploadId.String()
+}
+
+func getUploadPartsKey(uploadId uuid.UUID) string {
+	return servicePrefix + uploadPartsPrefix + uploadId.String()
+}
+
+// KEYS[1] - upload key, KEYS[2] - parts key, ARGV[1] - expected offset, ARGV[2] - chunk size, ARGV[3] - chunk name,
+// ARGV[4] - ttl in ms
+var advanceScript = redis.NewScript(`
+local offset = redis.call('HGET', KEYS[1], 'offset')
+if not offset then
+	return -1
+end
+if offset ~= ARGV[1] then
+	return -2
+end
+local newOffset = redis.call('HINCRBY', KEYS[1], 'offset', ARGV[2])
+redis.call('RPUSH', KEYS[2], ARGV[3])
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+redis.call('PEXPIRE', KEYS[2], ARGV[4])
+return newOffset
+`)
+
+// CreateUpload saves upload with zero offset, it expires if no chunk is received during ttl
+func (c *RedisClient) CreateUpload(ctx context.Context, upload model.Upload, ttl time.Duration) error {
+	meta, err := json.Marshal(upload)
+	if err != nil {
+		return err
+	}
+
+	key := getUploadKey(upload.UploadId)
+	_, err = c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, "meta", meta, "offset", 0)
+		pipe.PExpire(ctx, key, ttl)
+		return nil
+	})
+	if err != nil {
+		c.logger.Error("create upload: ", err)
+	}
+
+	return err
+}
+
+func (c *RedisClient) GetUpload(ctx context.Context, uploadId uuid.UUID) (model.Upload, error) {
+	values, err := c.client.HGetAll(ctx, getUploadKey(uploadId)).Result()
+	if err != nil {
+		c.logger.Error("get upload: ", err)
+		return model.Upload{}, err
+	}
+	if len(values) == 0 {
+		return model.Upload{}, model.NotFound
+	}
+
+	var upload model.Upload
+	if err = json.Unmarshal([]byte(values["meta"]), &upload); err != nil {
+		return model.Upload{}, err
+	}
+
+	if upload.Offset, err = strconv.ParseInt(values["offset"], 10, 64); err != nil {
+		return model.Upload{}, err
+	}
+
+	if upload.Parts, err = c.client.LRange(ctx, getUploadPartsKey(uploadId), 0, -1).Result(); err != nil {
+		c.logger.Error("get upload parts: ", err)
+		return model.Upload{}, err
+	}
+
+	return upload, nil
+}
+
+// AdvanceUpload appends chunk which starts at offset and returns new offset.
+// If upload has already been advanced by another request, model.OffsetMismatch is returned.
+func (c *RedisClient) AdvanceUpload(ctx context.Context, uploadId uuid.UUID, offset int64, size int64, part string,
+	ttl time.Duration) (int64, error) {
+	res, err := advanceScript.Run(ctx, c.client, []string{getUploadKey(uploadId), getUploadPartsKey(uploadId)},
+		strconv.FormatInt(offset, 10), size, part, ttl.Milliseconds()).Int64()
+	if err != nil {
+		c.logger.Error("advance upload: ", err)
+		return 0, err
+	}
+
+	switch res {
+	case -1:
+		return 0, model.NotFound
+	case -2:
+		return 0, model.OffsetMismatch
+	}
+
+	return res, nil
+}
+
+func (c *RedisClient) DeleteUpload(ctx context.Context, uploadId uuid.UUID) error {
+	err := c.client.Del(ctx, getUploadKey(uploadId), getUploadPartsKey(uploadId)).Err()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		c.logger.Error("delete upload: ", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LocalStorage keeps objects as files of one directory, which is served to clients by nginx
+type LocalStorage struct {
+	config LocalConfig
+	logger *logrus.Entry
+}
+
+func NewLocalStorage(config LocalConfig, logger *logrus.Logger) *LocalStorage {
+	return &LocalStorage{config: config, logger: logger.WithField("component", "local-storage")}
+}
+
+// Dir is directory where objects are kept
+func (s *LocalStorage) Dir() string {
+	return s.config.Dir
+}
+
+// path returns file of object, names which point outside of directory are rejected
+func (s *LocalStorage) path(name string) (string, error) {
+	if name == "" || strings.Contains(name, "..") || filepath.IsAbs(name) {
+		return "", ErrNotFound
+	}
+
+	return filepath.Join(s.config.Dir, filepath.FromSlash(name)), nil
+}
+
+func (s *LocalStorage) Put(ctx context.Context, name string, r io.Reader, size int64) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	// object appears under its name only when it is written completely
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		s.logger.Error("put ", name, ": ", err)
+		return err
+	}
+	// temporary file is created with mode 0600, but python services read media directory as other users
+	if err = tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *LocalStorage) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	return file, err
+}
+
+func (s *LocalStorage) Stat(ctx context.Context, name string) (ObjectInfo, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && info.IsDir()) {
+		return ObjectInfo{}, ErrNotFound
+	}
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	return ObjectInfo{Name: name, Size: info.Size(), Modified: info.ModTime()}, nil
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, name string) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	// nested directories are removed when they become empty, removal of not empty one just fails
+	for dir := filepath.Dir(path); dir != filepath.Clean(s.config.Dir); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+
+	return nil
+}
+
+// Presign is not supported, files of local directory are streamed to clients by backend
+func (s *LocalStorage) Presign(ctx context.Context, name string, ttl time.Duration) (string, error) {
+	return "", ErrPresignNotSupported
+}
+
+// List walks only directory of prefix, so listing of nested directory does not read the whole storage
+func (s *LocalStorage) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	if strings.Contains(prefix, "..") || filepath.IsAbs(prefix) {
+		return nil, ErrNotFound
+	}
+
+	root := s.config.Dir
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		root = filepath.Join(s.config.Dir, filepath.FromSlash(prefix[:i]))
+	}
+
+	objects := make([]ObjectInfo, 0)
+	err := filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			// directory of prefix does not exist, so there are no objects with it
+			if path == root && errors.Is(err, os.ErrNotExist) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".upload-") {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		name, err := filepath.Rel(s.config.Dir, path)
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(filepath.ToSlash(name), prefix) {
+			return nil
+		}
+
+		objects = append(objects, ObjectInfo{Name: filepath.ToSlash(name), Size: info.Size(), Modified: info.ModTime()})
+		return nil
+	})
+
+	return objects, err
+}
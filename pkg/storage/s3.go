@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/sirupsen/logrus"
+)
+
+// S3Storage keeps objects in bucket of S3-compatible storage, e.g. MinIO
+type S3Storage struct {
+	config S3Config
+	client *minio.Client
+	logger *logrus.Entry
+}
+
+func NewS3Storage(ctx context.Context, config S3Config, logger *logrus.Logger) (*S3Storage, error) {
+	client, err := minio.New(config.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(config.AccessKey, config.SecretKey, ""),
+		Secure: config.UseSSL,
+		Region: config.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := client.BucketExists(ctx, config.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		if err = client.MakeBucket(ctx, config.Bucket, minio.MakeBucketOptions{Region: config.Region}); err != nil {
+			return nil, fmt.Errorf("create bucket %q: %w", config.Bucket, err)
+		}
+	}
+
+	return &S3Storage{config: config, client: client, logger: logger.WithField("component", "s3-storage")}, nil
+}
+
+func isNotFound(err error) bool {
+	code := minio.ToErrorResponse(err).Code
+	return code == "NoSuchKey" || code == "NotFound"
+}
+
+func (s *S3Storage) Put(ctx context.Context, name string, r io.Reader, size int64) error {
+	_, err := s.client.PutObject(ctx, s.config.Bucket, name, r, size, minio.PutObjectOptions{})
+	if err != nil {
+		s.logger.Error("put ", name, ": ", err)
+	}
+
+	return err
+}
+
+func (s *S3Storage) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	// object is requested lazily, so absence is checked first to return ErrNotFound
+	if _, err := s.Stat(ctx, name); err != nil {
+		return nil, err
+	}
+
+	return s.client.GetObject(ctx, s.config.Bucket, name, minio.GetObjectOptions{})
+}
+
+func (s *S3Storage) Stat(ctx context.Context, name string) (ObjectInfo, error) {
+	info, err := s.client.StatObject(ctx, s.config.Bucket, name, minio.StatObjectOptions{})
+	if err != nil {
+		if isNotFound(err) {
+			return ObjectInfo{}, ErrNotFound
+		}
+		return ObjectInfo{}, err
+	}
+
+	return ObjectInfo{Name: name, Size: info.Size, Modified: info.LastModified}, nil
+}
+
+func (s *S3Storage) Delete(ctx context.Context, name string) error {
+	err := s.client.RemoveObject(ctx, s.config.Bucket, name, minio.RemoveObjectOptions{})
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *S3Storage) Presign(ctx context.Context, name string, ttl time.Duration) (string, error) {
+	u, err := s.client.PresignedGetObject(ctx, s.config.Bucket, name, ttl, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return u.String(), nil
+}
+
+func (s *S3Storage) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	objects := make([]ObjectInfo, 0)
+	options := minio.ListObjectsOptions{Prefix: prefix, Recursive: true}
+	for object := range s.client.ListObjects(ctx, s.config.Bucket, options) {
+		if object.Err != nil {
+			return nil, object.Err
+		}
+
+		objects = append(objects, ObjectInfo{Name: object.Key, Size: object.Size, Modified: object.LastModified})
+	}
+
+	return objects, nil
+}
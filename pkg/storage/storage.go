@@ -0,0 +1,255 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+
+	defaultPresignTTL = time.Hour
+)
+
+var (
+	ErrNotFound = errors.New("object not found")
+	// ErrPresignNotSupported is returned by drivers which objects are downloaded only through backend
+	ErrPresignNotSupported = errors.New("presigned URLs are not supported")
+)
+
+type ObjectInfo struct {
+	Name     string
+	Size     int64
+	Modified time.Time
+}
+
+// Storage keeps media files, objects are addressed by names which are stored in project and audio_part
+type Storage interface {
+	Put(ctx context.Context, name string, r io.Reader, size int64) error
+	Get(ctx context.Context, name string) (io.ReadCloser, error)
+	Stat(ctx context.Context, name string) (ObjectInfo, error)
+	Delete(ctx context.Context, name string) error
+	// Presign returns URL which client can download object with during ttl
+	Presign(ctx context.Context, name string, ttl time.Duration) (string, error)
+	// List returns objects which names start with prefix, empty prefix lists all objects
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+}
+
+type LocalConfig struct {
+	Dir string
+}
+
+type S3Config struct {
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+	Bucket    string
+	Region    string
+	UseSSL    bool
+}
+
+type Config struct {
+	Driver string
+	// PresignTTL is lifetime of signed media URLs given to clients
+	PresignTTL time.Duration
+	// TempDir is where files are staged for processing, system temporary directory by default
+	TempDir string
+	Local   LocalConfig
+	S3      S3Config
+}
+
+// InitConfig reads storage section of config, by default files are kept in local media directory
+func InitConfig(vp *viper.Viper, defaultDir string) Config {
+	config := Config{
+		Driver:     vp.GetString("storage.driver"),
+		PresignTTL: vp.GetDuration("storage.presignTTL"),
+		TempDir:    vp.GetString("storage.tempDir"),
+		Local: LocalConfig{
+			Dir: vp.GetString("storage.local.dir"),
+		},
+		S3: S3Config{
+			Endpoint:  vp.GetString("storage.s3.endpoint"),
+			AccessKey: vp.GetString("storage.s3.accessKey"),
+			SecretKey: vp.GetString("storage.s3.secretKey"),
+			Bucket:    vp.GetString("storage.s3.bucket"),
+			Region:    vp.GetString("storage.s3.region"),
+			UseSSL:    vp.GetBool("storage.s3.useSSL"),
+		},
+	}
+
+	if config.Driver == "" {
+		config.Driver = DriverLocal
+	}
+	if config.PresignTTL <= 0 {
+		config.PresignTTL = defaultPresignTTL
+	}
+	if config.TempDir == "" {
+		config.TempDir = os.TempDir()
+	}
+	if config.Local.Dir == "" {
+		config.Local.Dir = defaultDir
+	}
+
+	return config
+}
+
+func NewStorage(ctx context.Context, config Config, logger *logrus.Logger) (Storage, error) {
+	switch config.Driver {
+	case DriverLocal:
+		return NewLocalStorage(config.Local, logger), nil
+	case DriverS3:
+		return NewS3Storage(ctx, config.S3, logger)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", config.Driver)
+	}
+}
+
+// PutFile uploads local file as object with given name
+func PutFile(ctx context.Context, storage Storage, name string, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	return storage.Put(ctx, name, file, info.Size())
+}
+
+// GetFile downloads object to local file
+func GetFile(ctx context.Context, storage Storage, name string, path string) error {
+	src, err := storage.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(path)
+		return err
+	}
+
+	return dst.Close()
+}
+
+// Copy makes a copy of object under new name
+func Copy(ctx context.Context, storage Storage, name string, newName string) error {
+	info, err := storage.Stat(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	src, err := storage.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	return storage.Put(ctx, newName, src, info.Size)
+}
+
+// chainReader reads objects one after another, each object is opened only when previous one is read
+type chainReader struct {
+	ctx     context.Context
+	storage Storage
+	names   []string
+	current io.ReadCloser
+}
+
+func (r *chainReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.names) == 0 {
+				return 0, io.EOF
+			}
+
+			current, err := r.storage.Get(r.ctx, r.names[0])
+			if err != nil {
+				return 0, err
+			}
+			r.current, r.names = current, r.names[1:]
+		}
+
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			r.current.Close()
+			r.current = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+
+		return n, err
+	}
+}
+
+func (r *chainReader) Close() error {
+	if r.current != nil {
+		return r.current.Close()
+	}
+
+	return nil
+}
+
+// Concat puts objects joined in given order under new name, size is their total size
+func Concat(ctx context.Context, storage Storage, names []string, newName string, size int64) error {
+	reader := &chainReader{ctx: ctx, storage: storage, names: names}
+	defer reader.Close()
+
+	return storage.Put(ctx, newName, reader, size)
+}
+
+// CopyDir copies every object nested in dir to newDir keeping their relative names
+func CopyDir(ctx context.Context, storage Storage, dir string, newDir string) error {
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	objects, err := storage.List(ctx, prefix)
+	if err != nil {
+		return err
+	}
+
+	for _, object := range objects {
+		newName := strings.TrimSuffix(newDir, "/") + "/" + strings.TrimPrefix(object.Name, prefix)
+		if err = Copy(ctx, storage, object.Name, newName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteDir deletes every object nested in dir
+func DeleteDir(ctx context.Context, storage Storage, dir string) error {
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	objects, err := storage.List(ctx, prefix)
+	if err != nil {
+		return err
+	}
+
+	for _, object := range objects {
+		if err = storage.Delete(ctx, object.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// testDrivers returns local storage and, when S3_TEST_ENDPOINT is set, S3 storage, e.g. MinIO started with
+// docker compose --profile s3 up minio
+func testDrivers(t *testing.T) map[string]Storage {
+	logger := logrus.New()
+	drivers := map[string]Storage{
+		DriverLocal: NewLocalStorage(LocalConfig{Dir: t.TempDir()}, logger),
+	}
+
+	endpoint := os.Getenv("S3_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Log("S3_TEST_ENDPOINT is not set, S3 driver is not tested")
+		return drivers
+	}
+
+	bucket := os.Getenv("S3_TEST_BUCKET")
+	if bucket == "" {
+		bucket = "tiflo-test"
+	}
+
+	s3, err := NewS3Storage(context.Background(), S3Config{
+		Endpoint:  endpoint,
+		AccessKey: os.Getenv("S3_TEST_ACCESS_KEY"),
+		SecretKey: os.Getenv("S3_TEST_SECRET_KEY"),
+		Bucket:    bucket,
+	}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	drivers[DriverS3] = s3
+
+	return drivers
+}
+
+func TestStorageRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	for driver, storage := range testDrivers(t) {
+		t.Run(driver, func(t *testing.T) {
+			prefix := "test-" + time.Now().Format("150405.000000") + "/"
+			name := prefix + "dir/file.txt"
+			content := "round trip"
+
+			if err := storage.Put(ctx, name, strings.NewReader(content), int64(len(content))); err != nil {
+				t.Fatal(err)
+			}
+			defer DeleteDir(ctx, storage, prefix)
+
+			info, err := storage.Stat(ctx, name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Size != int64(len(content)) {
+				t.Errorf("size is %d, want %d", info.Size, len(content))
+			}
+
+			reader, err := storage.Get(ctx, name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := io.ReadAll(reader)
+			reader.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != content {
+				t.Errorf("content is %q, want %q", data, content)
+			}
+
+			objects, err := storage.List(ctx, prefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(objects) != 1 || objects[0].Name != name {
+				t.Errorf("listed %v, want only %s", objects, name)
+			}
+
+			presigned, err := storage.Presign(ctx, name, time.Minute)
+			switch {
+			case errors.Is(err, ErrPresignNotSupported):
+			case err != nil:
+				t.Fatal(err)
+			default:
+				response, err := http.Get(presigned)
+				if err != nil {
+					t.Fatal(err)
+				}
+				data, err = io.ReadAll(response.Body)
+				response.Body.Close()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if response.StatusCode != http.StatusOK || string(data) != content {
+					t.Errorf("presigned URL gave %d %q", response.StatusCode, data)
+				}
+			}
+
+			if err = storage.Delete(ctx, name); err != nil {
+				t.Fatal(err)
+			}
+			if _, err = storage.Stat(ctx, name); !errors.Is(err, ErrNotFound) {
+				t.Errorf("stat of deleted object returned %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestLocalStoragePutMode(t *testing.T) {
+	dir := t.TempDir()
+	storage := NewLocalStorage(LocalConfig{Dir: dir}, logrus.New())
+	if err := storage.Put(context.Background(), "file.wav", strings.NewReader("data"), 4); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "file.wav"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0644 {
+		t.Errorf("mode is %o, want 644", mode)
+	}
+}
@@ -0,0 +1,127 @@
+package transcript
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+
+	"tiflo/model"
+)
+
+const (
+	KindDialogue    = "dialogue"
+	KindDescription = "description"
+)
+
+// Entry is one line of described transcript, Start and Duration are given in tenths of a second
+type Entry struct {
+	Kind     string
+	Start    int64
+	Duration int64
+	Speaker  string
+	Text     string
+}
+
+// Label is how entry is introduced to reader
+func (e Entry) Label() string {
+	if e.Kind == KindDescription {
+		return "Тифлокомментарий"
+	}
+	if e.Speaker != "" {
+		return "Реплика (" + e.Speaker + ")"
+	}
+
+	return "Реплика"
+}
+
+// Time formats start of entry as hh:mm:ss
+func (e Entry) Time() string {
+	seconds := e.Start / 10
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+// Datetime formats start of entry as duration of HTML time element, e.g. PT65.5S
+func (e Entry) Datetime() string {
+	return fmt.Sprintf("PT%d.%dS", e.Start/10, e.Start%10)
+}
+
+// Build interleaves dialogue and descriptions by time of original video, description goes first when both start
+// at once, because it is voiced in pause before dialogue. Parts without text are not descriptions and are skipped.
+// Parts are placed on described timeline, so their start is mapped to video time by subtracting durations
+// of earlier descriptions, dialogue is already given in video time.
+func Build(segments []model.TranscriptSegment, parts []model.AudioPart) []Entry {
+	sorted := make([]model.AudioPart, len(parts))
+	copy(sorted, parts)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start < sorted[j].Start
+	})
+
+	entries := make([]Entry, 0, len(segments)+len(parts))
+	var described int64
+	for _, part := range sorted {
+		if part.Text == "" {
+			continue
+		}
+		entries = append(entries, Entry{Kind: KindDescription, Start: part.Start - described, Duration: part.Duration,
+			Text: part.Text})
+		described += part.Duration
+	}
+	for _, segment := range segments {
+		if segment.Text == "" {
+			continue
+		}
+		entries = append(entries, Entry{Kind: KindDialogue, Start: segment.Start, Duration: segment.Duration,
+			Speaker: segment.Speaker, Text: segment.Text})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Start != entries[j].Start {
+			return entries[i].Start < entries[j].Start
+		}
+		return entries[i].Kind == KindDescription && entries[j].Kind != KindDescription
+	})
+
+	return entries
+}
+
+// WriteText writes transcript as plain text, one entry per paragraph
+func WriteText(w io.Writer, title string, entries []Entry) error {
+	writer := bufio.NewWriter(w)
+	fmt.Fprintf(writer, "%s\n", title)
+	for _, entry := range entries {
+		fmt.Fprintf(writer, "\n[%s] %s: %s\n", entry.Time(), entry.Label(), entry.Text)
+	}
+
+	return writer.Flush()
+}
+
+// htmlTemplate keeps transcript readable by screen readers and braille displays: plain list in reading order,
+// every entry starts with time and who is speaking
+var htmlTemplate = template.Must(template.New("transcript").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+</head>
+<body>
+<main>
+<h1>{{.Title}}</h1>
+<ol>
+{{- range .Entries}}
+<li class="{{.Kind}}"><p><time datetime="{{.Datetime}}">{{.Time}}</time> <strong>{{.Label}}:</strong> {{.Text}}</p></li>
+{{- end}}
+</ol>
+</main>
+</body>
+</html>
+`))
+
+// WriteHTML writes transcript as accessible HTML document
+func WriteHTML(w io.Writer, title string, entries []Entry) error {
+	return htmlTemplate.Execute(w, struct {
+		Title   string
+		Entries []Entry
+	}{title, entries})
+}
@@ -0,0 +1,50 @@
+package transcript
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"tiflo/model"
+)
+
+func TestBuildMapsDescriptionsToVideoTime(t *testing.T) {
+	// video of 20 seconds with descriptions inserted at 5 and 15 seconds
+	parts := []model.AudioPart{
+		{Start: 0, Duration: 50},
+		{Start: 50, Duration: 20, Text: "Первый"},
+		{Start: 70, Duration: 100},
+		{Start: 170, Duration: 30, Text: "Второй"},
+		{Start: 200, Duration: 50},
+	}
+	segments := []model.TranscriptSegment{
+		{Start: 120, Duration: 20, Speaker: "A", Text: "Реплика между описаниями"},
+	}
+
+	entries := Build(segments, parts)
+
+	want := []struct {
+		kind  string
+		start int64
+	}{
+		{KindDescription, 50},
+		{KindDialogue, 120},
+		{KindDescription, 150},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for i, entry := range entries {
+		if entry.Kind != want[i].kind || entry.Start != want[i].start {
+			t.Errorf("entry %d: got %s at %d, want %s at %d", i, entry.Kind, entry.Start, want[i].kind, want[i].start)
+		}
+	}
+
+	var html bytes.Buffer
+	if err := WriteHTML(&html, "Проект", entries); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html.String(), `<time datetime="PT15.0S">00:00:15</time>`) {
+		t.Errorf("time of second description is not in video time:\n%s", html.String())
+	}
+}
@@ -0,0 +1,220 @@
+package waveform
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+const (
+	// Version of audiowaveform data format which is written
+	Version = 2
+	// CacheSuffix is added to name of media to get directory where its waveforms are cached
+	CacheSuffix = ".waveform"
+
+	flag8Bit = 1
+)
+
+var UnsupportedFormat = errors.New("UnsupportedFormat")
+
+// Waveform is min/max peaks of mono audio in audiowaveform format, Data keeps min and max of every pixel
+type Waveform struct {
+	Version         int     `json:"version"`
+	Channels        int     `json:"channels"`
+	SampleRate      int     `json:"sample_rate"`
+	SamplesPerPixel int     `json:"samples_per_pixel"`
+	Bits            int     `json:"bits"`
+	Length          int     `json:"length"`
+	Data            []int16 `json:"data"`
+}
+
+func DefaultZooms() []int {
+	return []int{256, 512, 1024, 2048, 4096}
+}
+
+// CacheName returns name of object where waveform of media at zoom is cached
+func CacheName(name string, zoom int) string {
+	return fmt.Sprintf("%s%s/%d.dat", name, CacheSuffix, zoom)
+}
+
+// CachedMedia returns name of media which cached waveform belongs to
+func CachedMedia(name string) (string, bool) {
+	dir, _, found := strings.Cut(name, "/")
+	if !found || !strings.HasSuffix(dir, CacheSuffix) {
+		return "", false
+	}
+
+	return strings.TrimSuffix(dir, CacheSuffix), true
+}
+
+// level collects peaks of one zoom
+type level struct {
+	waveform Waveform
+	count    int
+	min, max int16
+}
+
+func (l *level) flush() {
+	l.waveform.Data = append(l.waveform.Data, l.min, l.max)
+	l.waveform.Length++
+	l.count = 0
+}
+
+// Builder computes waveforms of several zooms in one pass over samples
+type Builder struct {
+	levels []*level
+}
+
+// NewBuilder makes builder of waveforms, zoom is number of samples per pixel
+func NewBuilder(sampleRate int, zooms []int) *Builder {
+	builder := &Builder{levels: make([]*level, 0, len(zooms))}
+	for _, zoom := range zooms {
+		builder.levels = append(builder.levels, &level{waveform: Waveform{
+			Version:         Version,
+			Channels:        1,
+			SampleRate:      sampleRate,
+			SamplesPerPixel: zoom,
+			Bits:            16,
+			Data:            make([]int16, 0),
+		}})
+	}
+
+	return builder
+}
+
+func (b *Builder) Add(sample int16) {
+	for _, l := range b.levels {
+		if l.count == 0 || sample < l.min {
+			l.min = sample
+		}
+		if l.count == 0 || sample > l.max {
+			l.max = sample
+		}
+
+		l.count++
+		if l.count == l.waveform.SamplesPerPixel {
+			l.flush()
+		}
+	}
+}
+
+// Waveforms returns waveforms in order of zooms, the last incomplete pixel is kept
+func (b *Builder) Waveforms() []Waveform {
+	waveforms := make([]Waveform, 0, len(b.levels))
+	for _, l := range b.levels {
+		if l.count > 0 {
+			l.flush()
+		}
+		waveforms = append(waveforms, l.waveform)
+	}
+
+	return waveforms
+}
+
+// WriteTo writes waveform in binary audiowaveform format
+func (w Waveform) WriteTo(writer io.Writer) (int64, error) {
+	var flags uint32
+	if w.Bits == 8 {
+		flags = flag8Bit
+	}
+
+	header := []any{int32(w.Version), flags, int32(w.SampleRate), int32(w.SamplesPerPixel), uint32(w.Length)}
+	if w.Version >= 2 {
+		header = append(header, int32(w.Channels))
+	}
+
+	counter := &countingWriter{writer: writer}
+	for _, value := range header {
+		if err := binary.Write(counter, binary.LittleEndian, value); err != nil {
+			return counter.written, err
+		}
+	}
+
+	var err error
+	if w.Bits == 8 {
+		data := make([]int8, len(w.Data))
+		for i, value := range w.Data {
+			data[i] = int8(value)
+		}
+		err = binary.Write(counter, binary.LittleEndian, data)
+	} else {
+		err = binary.Write(counter, binary.LittleEndian, w.Data)
+	}
+
+	return counter.written, err
+}
+
+// Read reads waveform in binary audiowaveform format of version 1 or 2
+func Read(reader io.Reader) (Waveform, error) {
+	var header struct {
+		Version         int32
+		Flags           uint32
+		SampleRate      int32
+		SamplesPerPixel int32
+		Length          uint32
+	}
+	if err := binary.Read(reader, binary.LittleEndian, &header); err != nil {
+		return Waveform{}, err
+	}
+
+	w := Waveform{
+		Version:         int(header.Version),
+		Channels:        1,
+		SampleRate:      int(header.SampleRate),
+		SamplesPerPixel: int(header.SamplesPerPixel),
+		Bits:            16,
+		Length:          int(header.Length),
+	}
+
+	switch w.Version {
+	case 1:
+	case 2:
+		var channels int32
+		if err := binary.Read(reader, binary.LittleEndian, &channels); err != nil {
+			return Waveform{}, err
+		}
+		w.Channels = int(channels)
+	default:
+		return Waveform{}, fmt.Errorf("%w: version %d", UnsupportedFormat, w.Version)
+	}
+
+	size := uint64(header.Length) * uint64(w.Channels) * 2
+	if w.Channels <= 0 || size > math.MaxInt32 {
+		return Waveform{}, fmt.Errorf("%w: %d channels of %d pixels", UnsupportedFormat, w.Channels, w.Length)
+	}
+
+	if header.Flags&flag8Bit != 0 {
+		w.Bits = 8
+		data := make([]int8, size)
+		if err := binary.Read(reader, binary.LittleEndian, data); err != nil {
+			return Waveform{}, err
+		}
+
+		w.Data = make([]int16, size)
+		for i, value := range data {
+			w.Data[i] = int16(value)
+		}
+		return w, nil
+	}
+
+	w.Data = make([]int16, size)
+	if err := binary.Read(reader, binary.LittleEndian, w.Data); err != nil {
+		return Waveform{}, err
+	}
+
+	return w, nil
+}
+
+type countingWriter struct {
+	writer  io.Writer
+	written int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.written += int64(n)
+	return n, err
+}
@@ -2,3 +2,4 @@ package proto_gen
 
 //go:generate protoc  --go_out=..  --go-grpc_out=.. --proto_path=. voice2text.proto
 //go:generate protoc  --go_out=..  --go-grpc_out=.. --proto_path=. image2text.proto
+//go:generate protoc  --go_out=..  --go-grpc_out=.. --proto_path=. speech2text.proto